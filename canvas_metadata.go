@@ -0,0 +1,185 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"os"
+
+	"github.com/noAbbreviation/benday/convert"
+)
+
+const (
+	pngSignature        = "\x89PNG\r\n\x1a\n"
+	paddingChunkKeyword = "benday:padding"
+	dotsChunkKeyword    = "benday:dots"
+
+	backgroundChunkKeyword = "benday:background"
+	alignChunkKeyword      = "benday:align"
+	inkChunkKeyword        = "benday:ink"
+)
+
+// The padding is also stored in a tEXt chunk right after the png header, so files
+// keep working after being renamed. Six-dot canvases get a second chunk marking them, and
+// canvases without the checkerboard one naming their background. Inks other than the
+// default are recorded too, so their dots keep reading as shaded whatever --ink is set to.
+func encodeCanvas(w io.Writer, img image.Image, paddingX int, paddingY int, cellH int, background convert.CanvasBackground, align convert.DotAlignment, ink color.NRGBA) error {
+	buffer := bytes.Buffer{}
+	if err := png.Encode(&buffer, img); err != nil {
+		return err
+	}
+
+	// Signature, then the IHDR chunk: length, type, 13 bytes of data, and checksum.
+	data := buffer.Bytes()
+	headerEnd := len(pngSignature) + 4 + 4 + 13 + 4
+
+	chunks := textChunk(paddingChunkKeyword, fmt.Sprintf("%vx%v", paddingX, paddingY))
+	if cellH == convert.SIX_DOT_BRAILLE_HEIGHT {
+		chunks = append(chunks, textChunk(dotsChunkKeyword, "6")...)
+	}
+
+	if background != convert.BackgroundCheckerboard {
+		chunks = append(chunks, textChunk(backgroundChunkKeyword, background.String())...)
+	}
+
+	if align != convert.AlignStart {
+		chunks = append(chunks, textChunk(alignChunkKeyword, align.String())...)
+	}
+
+	if ink != convert.DefaultInk {
+		chunks = append(chunks, textChunk(inkChunkKeyword, fmt.Sprintf("%02x%02x%02x", ink.R, ink.G, ink.B))...)
+	}
+
+	for _, part := range [][]byte{data[:headerEnd], chunks, data[headerEnd:]} {
+		if _, err := w.Write(part); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func textChunk(keyword string, text string) []byte {
+	payload := []byte("tEXt")
+	payload = append(payload, keyword...)
+	payload = append(payload, 0)
+	payload = append(payload, text...)
+
+	chunk := binary.BigEndian.AppendUint32(nil, uint32(len(payload)-4))
+	chunk = append(chunk, payload...)
+	chunk = binary.BigEndian.AppendUint32(chunk, crc32.ChecksumIEEE(payload))
+
+	return chunk
+}
+
+// Returns false if the png has no tEXt chunk with the keyword before its image data.
+func textChunkValue(data []byte, wantedKeyword string) (string, bool) {
+	if !bytes.HasPrefix(data, []byte(pngSignature)) {
+		return "", false
+	}
+
+	for offset := len(pngSignature); offset+8 <= len(data); {
+		length := int(binary.BigEndian.Uint32(data[offset:]))
+		chunkType := string(data[offset+4 : offset+8])
+
+		dataStart := offset + 8
+		dataEnd := dataStart + length
+
+		if length < 0 || dataEnd+4 > len(data) || chunkType == "IDAT" || chunkType == "IEND" {
+			break
+		}
+
+		if chunkType == "tEXt" {
+			keyword, text, found := bytes.Cut(data[dataStart:dataEnd], []byte{0})
+			if found && string(keyword) == wantedKeyword {
+				return string(text), true
+			}
+		}
+
+		offset = dataEnd + 4
+	}
+
+	return "", false
+}
+
+// Returns false if the png has no valid padding chunk.
+func paddingFromChunk(data []byte) (int, int, bool) {
+	paddingSpec, found := textChunkValue(data, paddingChunkKeyword)
+	if !found {
+		return 0, 0, false
+	}
+
+	paddingX, paddingY, err := parsePaddingSpec(paddingSpec)
+	return paddingX, paddingY, err == nil
+}
+
+// Dot rows per character. Canvases without the dots chunk are eight-dot.
+func cellHeightFromChunk(data []byte) int {
+	if dots, _ := textChunkValue(data, dotsChunkKeyword); dots == "6" {
+		return convert.SIX_DOT_BRAILLE_HEIGHT
+	}
+
+	return convert.BRAILLE_HEIGHT
+}
+
+// Canvases without the background chunk have the checkerboard.
+func backgroundFromChunk(data []byte) convert.CanvasBackground {
+	name, _ := textChunkValue(data, backgroundChunkKeyword)
+	for _, background := range []convert.CanvasBackground{convert.BackgroundSolid, convert.BackgroundTransparent} {
+		if name == background.String() {
+			return background
+		}
+	}
+
+	return convert.BackgroundCheckerboard
+}
+
+// Canvases without the align chunk have their dots at the top left.
+func alignmentFromChunk(data []byte) convert.DotAlignment {
+	name, _ := textChunkValue(data, alignChunkKeyword)
+	for _, align := range []convert.DotAlignment{convert.AlignCenter, convert.AlignEnd} {
+		if name == align.String() {
+			return align
+		}
+	}
+
+	return convert.AlignStart
+}
+
+// Canvases without the ink chunk were written with the default ink, or with the --ink of
+// before the chunk existed, so they are read with the given ink.
+func inkFromChunk(data []byte, ink color.NRGBA) color.NRGBA {
+	spec, found := textChunkValue(data, inkChunkKeyword)
+	if !found {
+		return ink
+	}
+
+	if chunkInk, err := parseInkColor(spec); err == nil {
+		return chunkInk
+	}
+
+	return ink
+}
+
+// Prefers the padding chunk, and falls back to the file name for files made before it existed.
+func canvasPadding(data []byte, fileName string) (int, int, error) {
+	if paddingX, paddingY, ok := paddingFromChunk(data); ok {
+		return paddingX, paddingY, nil
+	}
+
+	return paddingFromFileName(fileName)
+}
+
+func readCanvasPadding(fileName string) (int, int, error) {
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		return 0, 0, decodeError{FileDoesNotExistError}
+	}
+
+	return canvasPadding(data, fileName)
+}
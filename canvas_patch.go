@@ -0,0 +1,181 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/noAbbreviation/benday/convert"
+)
+
+const patchHeaderPrefix = "benday-patch"
+
+var (
+	InvalidPatchError = errors.New("Invalid patch file.")
+)
+
+type cellPatch struct {
+	charX int
+	charY int
+	char  rune
+}
+
+// A patch lists the braille characters that changed between two canvases of the same size.
+type canvasPatch struct {
+	charsX int
+	charsY int
+	cells  []cellPatch
+}
+
+func diffCanvases(before [][]rune, after [][]rune) (canvasPatch, error) {
+	if len(before) == 0 || len(after) == 0 {
+		return canvasPatch{}, fmt.Errorf("Cannot diff an empty canvas.")
+	}
+
+	charsX, charsY := len(before[0]), len(before)
+	if len(after[0]) != charsX || len(after) != charsY {
+		return canvasPatch{}, fmt.Errorf(
+			"Canvas sizes differ: %vx%v and %vx%v characters.", charsX, charsY, len(after[0]), len(after),
+		)
+	}
+
+	patch := canvasPatch{charsX: charsX, charsY: charsY}
+	for charY := range charsY {
+		for charX := range charsX {
+			if before[charY][charX] != after[charY][charX] {
+				patch.cells = append(patch.cells, cellPatch{charX, charY, after[charY][charX]})
+			}
+		}
+	}
+
+	return patch, nil
+}
+
+func (patch canvasPatch) String() string {
+	builder := strings.Builder{}
+	builder.WriteString(fmt.Sprintf("%v %vx%v\n", patchHeaderPrefix, patch.charsX, patch.charsY))
+
+	for _, cell := range patch.cells {
+		builder.WriteString(fmt.Sprintf("%v %v %c\n", cell.charX, cell.charY, cell.char))
+	}
+
+	return builder.String()
+}
+
+func parsePatch(r io.Reader) (canvasPatch, error) {
+	patch := canvasPatch{}
+	scanner := bufio.NewScanner(r)
+
+	if !scanner.Scan() {
+		return patch, InvalidPatchError
+	}
+
+	_, err := fmt.Sscanf(scanner.Text(), patchHeaderPrefix+" %dx%d", &patch.charsX, &patch.charsY)
+	if err != nil || patch.charsX <= 0 || patch.charsY <= 0 {
+		return patch, fmt.Errorf("%w Expected a \"%v <width>x<height>\" header.", InvalidPatchError, patchHeaderPrefix)
+	}
+
+	for lineNumber := 2; scanner.Scan(); lineNumber += 1 {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		cell := cellPatch{}
+		if _, err := fmt.Sscanf(line, "%d %d %c", &cell.charX, &cell.charY, &cell.char); err != nil {
+			return patch, fmt.Errorf("%w Cannot read line %v: %v", InvalidPatchError, lineNumber, err)
+		}
+
+		if !convert.IsBraille(cell.char) {
+			return patch, fmt.Errorf("%w Line %v is not a braille character.", InvalidPatchError, lineNumber)
+		}
+
+		if cell.charX < 0 || cell.charX >= patch.charsX || cell.charY < 0 || cell.charY >= patch.charsY {
+			return patch, fmt.Errorf("%w Line %v is outside of the canvas.", InvalidPatchError, lineNumber)
+		}
+
+		patch.cells = append(patch.cells, cell)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return patch, err
+	}
+
+	return patch, nil
+}
+
+// Only the dots that change are repainted, so comment pixels on the target are left alone.
+func applyPatch(fileName string, patch canvasPatch) error {
+	// The patched dots and background would read the other way around.
+	if baseReadOptions.Invert {
+		return InvertedReadingError
+	}
+
+	file, err := os.Open(fileName)
+	if err != nil {
+		return decodeError{FileDoesNotExistError}
+	}
+
+	canvas, err := decodeCanvas(file, fileName, baseReadOptions)
+	file.Close()
+
+	if err != nil {
+		return err
+	}
+
+	m := canvas.measure
+	if m.CharsX != patch.charsX || m.CharsY != patch.charsY {
+		return fmt.Errorf(
+			"Patch is for a %vx%v canvas, but \"%v\" is %vx%v characters.",
+			patch.charsX, patch.charsY, fileName, m.CharsX, m.CharsY,
+		)
+	}
+
+	newImage := image.NewNRGBA(image.Rect(0, 0, m.ImageWidth, m.ImageHeight))
+	draw.Draw(newImage, newImage.Bounds(), canvas.img, canvas.img.Bounds().Min, draw.Src)
+
+	defaultCanvasImg := convert.NewCanvasImage(m.ImageWidth, m.ImageHeight, canvas.paddingX, canvas.paddingY, m.IsUnpadded, m.CellH, m.Background, m.Align)
+
+	opts := baseReadOptions
+	opts.Ink = m.Ink
+
+	for _, cell := range patch.cells {
+		brailleIdx, _ := convert.RuneToBits(cell.char)
+
+		for brailleYOff := range m.CellH {
+			for brailleXOff := range convert.BRAILLE_WIDTH {
+				x, y := m.DotPixel(cell.charX*convert.BRAILLE_WIDTH+brailleXOff, cell.charY*m.CellH+brailleYOff)
+
+				toShade := brailleIdx&convert.DotBit(brailleXOff, brailleYOff) != 0
+				isShaded := convert.ShadeTypeAt(newImage.At(x, y), opts) == convert.ColorShaded
+
+				if toShade && !isShaded {
+					newImage.SetNRGBA(x, y, m.Ink)
+				}
+
+				if !toShade && isShaded {
+					newImage.Set(x, y, defaultCanvasImg.At(x, y))
+				}
+			}
+		}
+	}
+
+	if err := backupCanvas(fileName); err != nil {
+		return err
+	}
+
+	file, err = os.Create(fileName)
+	if err != nil {
+		return err
+	}
+
+	defer file.Close()
+
+	encodeError := encodeCanvas(file, newImage, canvas.paddingX, canvas.paddingY, m.CellH, m.Background, m.Align, m.Ink)
+	return encodeError
+}
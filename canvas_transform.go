@@ -0,0 +1,631 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/noAbbreviation/benday/convert"
+)
+
+var (
+	RotatedFileExistsError  = errors.New("Cannot rotate, the rotated file already exists.")
+	RepaddedFileExistsError = errors.New("Cannot re-pad, a file with the new padding already exists.")
+	SaveAsFileExistsError   = errors.New("Cannot save, the file already exists.")
+	ReadOnlyTextCanvasError = errors.New("Text files are read-only, save as a benday file (w) to edit.")
+	InvertedReadingError    = errors.New("Dots are written dark on light, stop reading light on dark (I or --invert) to change the canvas.")
+)
+
+type flipDirection int
+
+const (
+	flipHorizontal flipDirection = iota
+	flipVertical
+)
+
+// Axes through the middle of the canvas that edits are mirrored across.
+type symmetryAxis int
+
+const (
+	symmetryNone symmetryAxis = iota
+	symmetryVertical
+	symmetryHorizontal
+	symmetryBoth
+)
+
+func (axis symmetryAxis) String() string {
+	switch axis {
+	case symmetryVertical:
+		return "vertical"
+	case symmetryHorizontal:
+		return "horizontal"
+	case symmetryBoth:
+		return "both"
+	default:
+		return "none"
+	}
+}
+
+// The point and its mirrors inside a size, without duplicates for points on an axis.
+func mirroredPoints(point image.Point, size image.Point, axis symmetryAxis) []image.Point {
+	points := []image.Point{point}
+	addPoint := func(mirrored image.Point) {
+		if !slices.Contains(points, mirrored) {
+			points = append(points, mirrored)
+		}
+	}
+
+	mirroredX := image.Pt(size.X-1-point.X, point.Y)
+	mirroredY := image.Pt(point.X, size.Y-1-point.Y)
+
+	if axis == symmetryVertical || axis == symmetryBoth {
+		addPoint(mirroredX)
+	}
+
+	if axis == symmetryHorizontal || axis == symmetryBoth {
+		addPoint(mirroredY)
+	}
+
+	if axis == symmetryBoth {
+		addPoint(image.Pt(mirroredX.X, mirroredY.Y))
+	}
+
+	return points
+}
+
+func readCanvasForTransform(fileName string, paddingX int, paddingY int) (convert.CanvasMeasure, image.Image, error) {
+	fileStats, err := os.Stat(fileName)
+	if err != nil {
+		return convert.CanvasMeasure{}, nil, decodeError{FileDoesNotExistError}
+	}
+
+	if time.Since(fileStats.ModTime()) < time.Second {
+		return convert.CanvasMeasure{}, nil, silentError{err}
+	}
+
+	m, err := getCanvasMeasurement(fileName, paddingX, paddingY)
+	if err != nil {
+		return convert.CanvasMeasure{}, nil, err
+	}
+
+	file, err := os.Open(fileName)
+	if err != nil {
+		return convert.CanvasMeasure{}, nil, decodeError{FileDoesNotExistError}
+	}
+
+	img, err := png.Decode(file)
+	file.Close()
+
+	if err != nil {
+		return convert.CanvasMeasure{}, nil, decodeError{err}
+	}
+
+	return m, img, nil
+}
+
+// Like readCanvasForTransform without the guard against recently modified files.
+func decodeCanvasImage(fileName string, m convert.CanvasMeasure) (*image.NRGBA, error) {
+	file, err := os.Open(fileName)
+	if err != nil {
+		return nil, decodeError{FileDoesNotExistError}
+	}
+
+	img, err := png.Decode(file)
+	file.Close()
+
+	if err != nil {
+		return nil, decodeError{err}
+	}
+
+	return copyCanvasImage(m, img), nil
+}
+
+// The transforms edit a copy, so the decoded image can still be read while writing.
+func copyCanvasImage(m convert.CanvasMeasure, img image.Image) *image.NRGBA {
+	newImage := image.NewNRGBA(image.Rect(0, 0, m.ImageWidth, m.ImageHeight))
+	draw.Draw(newImage, newImage.Bounds(), img, img.Bounds().Min, draw.Src)
+
+	return newImage
+}
+
+// Calls moveDot for every dot that differs from the default canvas, so the checkerboard
+// of the destination is left intact.
+func forEachContentDot(m convert.CanvasMeasure, img image.Image, paddingX int, paddingY int, moveDot func(dotX int, dotY int, c color.Color)) {
+	defaultCanvasImg := convert.NewCanvasImage(m.ImageWidth, m.ImageHeight, paddingX, paddingY, m.IsUnpadded, m.CellH, m.Background, m.Align)
+	origin := img.Bounds().Min
+
+	for dotY := range m.CharsY * m.CellH {
+		for dotX := range m.CharsX * convert.BRAILLE_WIDTH {
+			x, y := m.DotPixel(dotX, dotY)
+
+			oldColor := color.NRGBAModel.Convert(img.At(origin.X+x, origin.Y+y))
+			if oldColor == color.NRGBAModel.Convert(defaultCanvasImg.At(x, y)) {
+				continue
+			}
+
+			moveDot(dotX, dotY, oldColor)
+		}
+	}
+}
+
+// Set from the --backup flag.
+var keepBackups = false
+
+// Copies a canvas to <name>.bak before it is rewritten or removed, replacing the backup of
+// the previous change. Canvases that do not exist yet have nothing to back up.
+func backupCanvas(fileName string) error {
+	if !keepBackups {
+		return nil
+	}
+
+	data, err := os.ReadFile(fileName)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(fileName+".bak", data, 0644)
+}
+
+func writeCanvasImage(fileName string, img image.Image, paddingX int, paddingY int, cellH int, background convert.CanvasBackground, align convert.DotAlignment, ink color.NRGBA) error {
+	if err := backupCanvas(fileName); err != nil {
+		return err
+	}
+
+	file, err := os.Create(fileName)
+	if err != nil {
+		return err
+	}
+
+	defer file.Close()
+
+	encodeError := encodeCanvas(file, img, paddingX, paddingY, cellH, background, align, ink)
+	return encodeError
+}
+
+// The file is copied as is, so the padding chunk comes along with it.
+func saveCanvasAs(fileName string, newFileName string) error {
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		return FileDoesNotExistError
+	}
+
+	file, err := os.OpenFile(newFileName, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if errors.Is(err, os.ErrExist) {
+		return SaveAsFileExistsError
+	}
+
+	if err != nil {
+		return err
+	}
+
+	defer file.Close()
+
+	_, err = file.Write(data)
+	return err
+}
+
+func saveTextCanvasAs(pixels [][]rune, newFileName string, paddingX int, paddingY int, cellH int) error {
+	file, err := os.OpenFile(newFileName, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if errors.Is(err, os.ErrExist) {
+		return SaveAsFileExistsError
+	}
+
+	if err != nil {
+		return err
+	}
+
+	defer file.Close()
+
+	img := convert.NewPixelsImage(pixels, paddingX, paddingY, cellH, convert.AlignStart, baseReadOptions.Ink)
+	return encodeCanvas(file, img, paddingX, paddingY, cellH, convert.BackgroundCheckerboard, convert.AlignStart, baseReadOptions.Ink)
+}
+
+// Flips work on dots instead of raw pixels, so padding between characters stays in place.
+func flipCanvas(fileName string, paddingX int, paddingY int, direction flipDirection) error {
+	m, oldImage, err := readCanvasForTransform(fileName, paddingX, paddingY)
+	if err != nil {
+		return err
+	}
+
+	newImage := copyCanvasImage(m, oldImage)
+	flipCanvasImage(m, newImage, paddingX, paddingY, direction)
+
+	return writeCanvasImage(fileName, newImage, paddingX, paddingY, m.CellH, m.Background, m.Align, m.Ink)
+}
+
+func flipCanvasImage(m convert.CanvasMeasure, img *image.NRGBA, paddingX int, paddingY int, direction flipDirection) {
+	oldImage := copyCanvasImage(m, img)
+	defaultCanvasImg := convert.NewCanvasImage(m.ImageWidth, m.ImageHeight, paddingX, paddingY, m.IsUnpadded, m.CellH, m.Background, m.Align)
+
+	dotsW := m.CharsX * convert.BRAILLE_WIDTH
+	dotsH := m.CharsY * m.CellH
+
+	for dotY := range dotsH {
+		for dotX := range dotsW {
+			x, y := m.DotPixel(dotX, dotY)
+			img.Set(x, y, defaultCanvasImg.At(x, y))
+		}
+	}
+
+	forEachContentDot(m, oldImage, paddingX, paddingY, func(dotX int, dotY int, c color.Color) {
+		switch direction {
+		case flipHorizontal:
+			dotX = dotsW - 1 - dotX
+		case flipVertical:
+			dotY = dotsH - 1 - dotY
+		}
+
+		x, y := m.DotPixel(dotX, dotY)
+		img.Set(x, y, c)
+	})
+}
+
+// Renamed files keep their name, as the padding chunk already records the new padding.
+func paddedFileName(fileName string, paddingX int, paddingY int) string {
+	prefix, _, ok := splitCanvasFileName(fileName)
+	if !ok {
+		return fileName
+	}
+
+	newName := fmt.Sprintf("%v.%vx%v.by.png", prefix, paddingX, paddingY)
+	return filepath.Join(filepath.Dir(fileName), newName)
+}
+
+func swappedPaddingFileName(fileName string, paddingX int, paddingY int) string {
+	return paddedFileName(fileName, paddingY, paddingX)
+}
+
+// A 2x4 character cannot be turned in place, so the dots of the whole canvas are rotated
+// instead. The canvas becomes 2*charsY characters wide and charsX/2 (rounded up) tall,
+// and the padding is swapped, which renames the file. Returns the new file name.
+// Six-dot canvases are rotated the same way, with their 2x3 characters.
+func rotateCanvas(fileName string, paddingX int, paddingY int, clockwise bool) (string, error) {
+	newFileName := swappedPaddingFileName(fileName, paddingX, paddingY)
+	if newFileName != fileName {
+		if _, err := os.Stat(newFileName); err == nil {
+			return fileName, RotatedFileExistsError
+		}
+	}
+
+	m, oldImage, err := readCanvasForTransform(fileName, paddingX, paddingY)
+	if err != nil {
+		return fileName, err
+	}
+
+	dotsW := m.CharsX * convert.BRAILLE_WIDTH
+	dotsH := m.CharsY * m.CellH
+
+	newMeasure := convert.CanvasMeasure{
+		IsUnpadded: m.IsUnpadded,
+		CharsX:     (dotsH + convert.BRAILLE_WIDTH - 1) / convert.BRAILLE_WIDTH,
+		CharsY:     (dotsW + m.CellH - 1) / m.CellH,
+		BrailleW:   convert.BRAILLE_WIDTH + paddingY,
+		BrailleH:   m.CellH + paddingX,
+		CellH:      m.CellH,
+		Align:      m.Align,
+	}
+
+	if m.IsUnpadded {
+		newMeasure.BrailleW = convert.BRAILLE_WIDTH
+		newMeasure.BrailleH = m.CellH
+	}
+
+	newMeasure.ImageWidth = newMeasure.CharsX * newMeasure.BrailleW
+	newMeasure.ImageHeight = newMeasure.CharsY * newMeasure.BrailleH
+
+	if m.IsUnpadded {
+		newMeasure.ImageWidth += 1
+		newMeasure.ImageHeight += 1
+	}
+
+	newImage := convert.NewCanvasImage(newMeasure.ImageWidth, newMeasure.ImageHeight, paddingY, paddingX, m.IsUnpadded, m.CellH, m.Background, m.Align)
+
+	forEachContentDot(m, oldImage, paddingX, paddingY, func(dotX int, dotY int, c color.Color) {
+		newDotX, newDotY := dotsH-1-dotY, dotX
+		if !clockwise {
+			newDotX, newDotY = dotY, dotsW-1-dotX
+		}
+
+		x, y := newMeasure.DotPixel(newDotX, newDotY)
+		newImage.Set(x, y, c)
+	})
+
+	if err := writeCanvasImage(newFileName, newImage, paddingY, paddingX, m.CellH, m.Background, m.Align, m.Ink); err != nil {
+		return fileName, err
+	}
+
+	if newFileName != fileName {
+		if err := backupCanvas(fileName); err != nil {
+			return newFileName, err
+		}
+
+		if err := os.Remove(fileName); err != nil {
+			return newFileName, err
+		}
+	}
+
+	return newFileName, nil
+}
+
+// Moves the dots of every character into characters of the new padding, renaming the file
+// to match. A toggled canvas (t) comes out padded. Returns the new file name.
+func repadCanvas(fileName string, paddingX int, paddingY int, newPaddingX int, newPaddingY int) (string, error) {
+	newFileName := paddedFileName(fileName, newPaddingX, newPaddingY)
+	if newFileName != fileName {
+		if _, err := os.Stat(newFileName); err == nil {
+			return fileName, RepaddedFileExistsError
+		}
+	}
+
+	m, oldImage, err := readCanvasForTransform(fileName, paddingX, paddingY)
+	if err != nil {
+		return fileName, err
+	}
+
+	newMeasure := m
+	newMeasure.IsUnpadded = false
+	newMeasure.BrailleW = convert.BRAILLE_WIDTH + newPaddingX
+	newMeasure.BrailleH = m.CellH + newPaddingY
+	newMeasure.ImageWidth = m.CharsX * newMeasure.BrailleW
+	newMeasure.ImageHeight = m.CharsY * newMeasure.BrailleH
+
+	newImage := convert.NewCanvasImage(newMeasure.ImageWidth, newMeasure.ImageHeight, newPaddingX, newPaddingY, false, m.CellH, m.Background, m.Align)
+
+	forEachContentDot(m, oldImage, paddingX, paddingY, func(dotX int, dotY int, c color.Color) {
+		x, y := newMeasure.DotPixel(dotX, dotY)
+		newImage.Set(x, y, c)
+	})
+
+	if err := writeCanvasImage(newFileName, newImage, newPaddingX, newPaddingY, m.CellH, m.Background, m.Align, m.Ink); err != nil {
+		return fileName, err
+	}
+
+	if newFileName != fileName {
+		if err := backupCanvas(fileName); err != nil {
+			return newFileName, err
+		}
+
+		if err := os.Remove(fileName); err != nil {
+			return newFileName, err
+		}
+	}
+
+	return newFileName, nil
+}
+
+// Trims the canvas to bounds, given in braille characters.
+func cropCanvas(fileName string, paddingX int, paddingY int, bounds image.Rectangle) error {
+	m, oldImage, err := readCanvasForTransform(fileName, paddingX, paddingY)
+	if err != nil {
+		return err
+	}
+
+	bounds = bounds.Intersect(image.Rect(0, 0, m.CharsX, m.CharsY))
+	if bounds.Empty() {
+		return nil
+	}
+
+	newMeasure := m
+	newMeasure.CharsX = bounds.Dx()
+	newMeasure.CharsY = bounds.Dy()
+	newMeasure.ImageWidth = newMeasure.CharsX * m.BrailleW
+	newMeasure.ImageHeight = newMeasure.CharsY * m.BrailleH
+
+	if m.IsUnpadded {
+		newMeasure.ImageWidth += 1
+		newMeasure.ImageHeight += 1
+	}
+
+	newImage := convert.NewCanvasImage(newMeasure.ImageWidth, newMeasure.ImageHeight, paddingX, paddingY, m.IsUnpadded, m.CellH, m.Background, m.Align)
+
+	dotBounds := image.Rect(
+		bounds.Min.X*convert.BRAILLE_WIDTH, bounds.Min.Y*m.CellH,
+		bounds.Max.X*convert.BRAILLE_WIDTH, bounds.Max.Y*m.CellH,
+	)
+
+	forEachContentDot(m, oldImage, paddingX, paddingY, func(dotX int, dotY int, c color.Color) {
+		if !image.Pt(dotX, dotY).In(dotBounds) {
+			return
+		}
+
+		x, y := newMeasure.DotPixel(dotX-dotBounds.Min.X, dotY-dotBounds.Min.Y)
+		newImage.Set(x, y, c)
+	})
+
+	return writeCanvasImage(fileName, newImage, paddingX, paddingY, m.CellH, m.Background, m.Align, m.Ink)
+}
+
+// Shaded dots are cleared and blank dots are shaded. Non-grayscale and transparent dots
+// are kept, as are the padding rows and columns.
+func invertCanvas(fileName string, paddingX int, paddingY int, opts convert.ReadOptions) error {
+	m, oldImage, err := readCanvasForTransform(fileName, paddingX, paddingY)
+	if err != nil {
+		return err
+	}
+
+	newImage := copyCanvasImage(m, oldImage)
+	invertCanvasImage(m, newImage, paddingX, paddingY, opts)
+
+	return writeCanvasImage(fileName, newImage, paddingX, paddingY, m.CellH, m.Background, m.Align, m.Ink)
+}
+
+func invertCanvasImage(m convert.CanvasMeasure, img *image.NRGBA, paddingX int, paddingY int, opts convert.ReadOptions) {
+	opts.Ink = m.Ink
+	defaultCanvasImg := convert.NewCanvasImage(m.ImageWidth, m.ImageHeight, paddingX, paddingY, m.IsUnpadded, m.CellH, m.Background, m.Align)
+
+	for dotY := range m.CharsY * m.CellH {
+		for dotX := range m.CharsX * convert.BRAILLE_WIDTH {
+			x, y := m.DotPixel(dotX, dotY)
+
+			shade := convert.ShadeTypeAt(img.At(x, y), opts)
+			if shade == convert.ColorTransparent && m.Background == convert.BackgroundTransparent {
+				shade = convert.ColorNonShaded
+			}
+
+			switch shade {
+			case convert.ColorShaded:
+				img.Set(x, y, defaultCanvasImg.At(x, y))
+			case convert.ColorNonShaded:
+				img.SetNRGBA(x, y, m.Ink)
+			}
+		}
+	}
+}
+
+// Dot numbers follow the braille convention: 1-3 and 7 down the left column, 4-6 and 8 down the right.
+var brailleDotOffsets = [8]image.Point{
+	{0, 0}, {0, 1}, {0, 2}, {1, 0}, {1, 1}, {1, 2}, {0, 3}, {1, 3},
+}
+
+// Edits are expected in quick succession, so there is no guard against recently modified files.
+// The mirrors of the dot across the symmetry axes are set to match it, in the same write.
+func toggleDot(fileName string, paddingX int, paddingY int, cell image.Point, dotNumber int, symmetry symmetryAxis, opts convert.ReadOptions) error {
+	m, err := getCanvasMeasurement(fileName, paddingX, paddingY)
+	if err != nil {
+		return err
+	}
+
+	newImage, err := decodeCanvasImage(fileName, m)
+	if err != nil {
+		return err
+	}
+
+	if !toggleDotImage(m, newImage, paddingX, paddingY, cell, dotNumber, symmetry, opts) {
+		return nil
+	}
+
+	return writeCanvasImage(fileName, newImage, paddingX, paddingY, m.CellH, m.Background, m.Align, m.Ink)
+}
+
+// Returns false without changing the image for cells outside of the canvas and dots
+// the characters do not have. The dot is read as the preview reads it, with opts.
+func toggleDotImage(m convert.CanvasMeasure, img *image.NRGBA, paddingX int, paddingY int, cell image.Point, dotNumber int, symmetry symmetryAxis, opts convert.ReadOptions) bool {
+	if !cell.In(image.Rect(0, 0, m.CharsX, m.CharsY)) || dotNumber < 1 || dotNumber > len(brailleDotOffsets) {
+		return false
+	}
+
+	// Six-dot characters have no dots 7 and 8.
+	offset := brailleDotOffsets[dotNumber-1]
+	if offset.Y >= m.CellH {
+		return false
+	}
+
+	dot := image.Pt(cell.X*convert.BRAILLE_WIDTH+offset.X, cell.Y*m.CellH+offset.Y)
+	dotsSize := image.Pt(m.CharsX*convert.BRAILLE_WIDTH, m.CharsY*m.CellH)
+
+	opts.Ink = m.Ink
+
+	x, y := m.DotPixel(dot.X, dot.Y)
+	shade := convert.ShadeTypeAt(img.At(x, y), opts) != convert.ColorShaded
+
+	defaultCanvasImg := convert.NewCanvasImage(m.ImageWidth, m.ImageHeight, paddingX, paddingY, m.IsUnpadded, m.CellH, m.Background, m.Align)
+
+	for _, mirrored := range mirroredPoints(dot, dotsSize, symmetry) {
+		x, y := m.DotPixel(mirrored.X, mirrored.Y)
+
+		if shade {
+			img.SetNRGBA(x, y, opts.Ink)
+		} else {
+			img.Set(x, y, defaultCanvasImg.At(x, y))
+		}
+	}
+
+	return true
+}
+
+// Characters connected to start, up, down, left, or right, that are all blank or all
+// fully shaded like start. Partially shaded characters are never filled.
+func floodFillCells(pixels [][]rune, start image.Point, cellH int) []image.Point {
+	if len(pixels) == 0 || !start.In(image.Rect(0, 0, len(pixels[0]), len(pixels))) {
+		return nil
+	}
+
+	lookup := convert.BrailleLookupFor(cellH)
+	target := pixels[start.Y][start.X]
+	if target != lookup[0] && target != lookup[len(lookup)-1] {
+		return nil
+	}
+
+	visited := map[image.Point]bool{start: true}
+	queue := []image.Point{start}
+	filled := []image.Point{}
+
+	for len(queue) != 0 {
+		cell := queue[0]
+		queue = queue[1:]
+		filled = append(filled, cell)
+
+		for _, step := range []image.Point{{0, -1}, {0, 1}, {-1, 0}, {1, 0}} {
+			next := cell.Add(step)
+			if next.Y < 0 || next.Y >= len(pixels) || next.X < 0 || next.X >= len(pixels[next.Y]) {
+				continue
+			}
+
+			if visited[next] || pixels[next.Y][next.X] != target {
+				continue
+			}
+
+			visited[next] = true
+			queue = append(queue, next)
+		}
+	}
+
+	return filled
+}
+
+// Shades or clears every dot of the given characters, and of their mirrors across the
+// symmetry axes. Like toggleDot, there is no guard against recently modified files.
+func fillCells(fileName string, paddingX int, paddingY int, cells []image.Point, shade bool, symmetry symmetryAxis) error {
+	m, err := getCanvasMeasurement(fileName, paddingX, paddingY)
+	if err != nil {
+		return err
+	}
+
+	newImage, err := decodeCanvasImage(fileName, m)
+	if err != nil {
+		return err
+	}
+
+	fillCellsImage(m, newImage, paddingX, paddingY, cells, shade, symmetry)
+
+	return writeCanvasImage(fileName, newImage, paddingX, paddingY, m.CellH, m.Background, m.Align, m.Ink)
+}
+
+func fillCellsImage(m convert.CanvasMeasure, img *image.NRGBA, paddingX int, paddingY int, cells []image.Point, shade bool, symmetry symmetryAxis) {
+	defaultCanvasImg := convert.NewCanvasImage(m.ImageWidth, m.ImageHeight, paddingX, paddingY, m.IsUnpadded, m.CellH, m.Background, m.Align)
+	canvasBounds := image.Rect(0, 0, m.CharsX, m.CharsY)
+
+	mirroredCells := []image.Point{}
+	for _, cell := range cells {
+		mirroredCells = append(mirroredCells, mirroredPoints(cell, canvasBounds.Max, symmetry)...)
+	}
+
+	for _, cell := range mirroredCells {
+		if !cell.In(canvasBounds) {
+			continue
+		}
+
+		for offsetY := range m.CellH {
+			for offsetX := range convert.BRAILLE_WIDTH {
+				x, y := m.DotPixel(cell.X*convert.BRAILLE_WIDTH+offsetX, cell.Y*m.CellH+offsetY)
+
+				if shade {
+					img.SetNRGBA(x, y, m.Ink)
+				} else {
+					img.Set(x, y, defaultCanvasImg.At(x, y))
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,275 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"math/bits"
+	"os"
+	"slices"
+	"testing"
+
+	"github.com/noAbbreviation/benday/convert"
+)
+
+func TestToggleDotReadsWithThePreviewThreshold(t *testing.T) {
+	fileName := writeTestCanvas(t, [][]rune{{'⠀', '⠀'}}, 0, 2)
+
+	m, err := getCanvasMeasurement(fileName, 0, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	img := readTestImage(t, fileName)
+	dotX, dotY := m.DotPixel(0, 0)
+
+	// Three quarters bright, shaded at a threshold of 10/12 but not at the default 8/12.
+	img.SetNRGBA(dotX, dotY, color.NRGBA{0xbf, 0xbf, 0xbf, 0xff})
+
+	opts := baseReadOptions
+	opts.Threshold = 10
+
+	if !toggleDotImage(m, img, 0, 2, image.Pt(0, 0), 1, symmetryNone, opts) {
+		t.Fatal("the dot was not toggled")
+	}
+
+	if shade := convert.ShadeTypeAt(img.At(dotX, dotY), opts); shade == convert.ColorShaded {
+		t.Error("a dot the preview shows as shaded was shaded again instead of cleared")
+	}
+}
+
+// Blank but for the characters in content, taken from testPixels.
+func testPixelsWithin(charsX int, charsY int, content image.Rectangle) [][]rune {
+	pixels := testPixels(charsX, charsY)
+	for y, line := range pixels {
+		for x := range line {
+			if !image.Pt(x, y).In(content) {
+				line[x] = convert.BrailleLookup[0]
+			}
+		}
+	}
+
+	return pixels
+}
+
+func TestCropCanvasToContent(t *testing.T) {
+	content := image.Rect(1, 1, 4, 3)
+	pixels := testPixelsWithin(6, 5, content)
+
+	paddings := []image.Point{{0, 0}, {0, 2}, {1, 3}}
+
+	for _, padding := range paddings {
+		for _, unpadded := range []bool{false, true} {
+			t.Run(fmt.Sprintf("%vx%v padding, unpadded %v", padding.X, padding.Y, unpadded), func(t *testing.T) {
+				fileName := writeTestCanvas(t, pixels, padding.X, padding.Y)
+
+				cellW, cellH := convert.BRAILLE_WIDTH+padding.X, convert.BRAILLE_HEIGHT+padding.Y
+				if unpadded {
+					if err := togglePaddingState(fileName, padding.X, padding.Y); err != nil {
+						t.Fatal(err)
+					}
+
+					ageTestFile(t, fileName)
+					cellW, cellH = convert.BRAILLE_WIDTH, convert.BRAILLE_HEIGHT
+				}
+
+				bounds, hasContent := contentBounds(readCanvasPixels(fileName, baseReadOptions))
+				if !hasContent || bounds != content {
+					t.Fatalf("the content is at %v, want %v", bounds, content)
+				}
+
+				if err := cropCanvas(fileName, padding.X, padding.Y, bounds); err != nil {
+					t.Fatal(err)
+				}
+
+				wantSize := image.Pt(content.Dx()*cellW, content.Dy()*cellH)
+				if unpadded {
+					wantSize = wantSize.Add(image.Pt(1, 1))
+				}
+
+				if size := readTestImage(t, fileName).Bounds().Size(); size != wantSize {
+					t.Errorf("cropped to %v, want %v", size, wantSize)
+				}
+
+				cropped := readCanvasPixels(fileName, baseReadOptions)
+				if want := cropPixels(pixels, content); !slices.EqualFunc(cropped, want, slices.Equal) {
+					t.Errorf("cropped to %q, want %q", cropped, want)
+				}
+			})
+		}
+	}
+}
+
+func countDots(pixels [][]rune) int {
+	dots := 0
+	for _, line := range pixels {
+		for _, pixel := range line {
+			brailleBits, _ := convert.RuneToBits(pixel)
+			dots += bits.OnesCount(uint(brailleBits))
+		}
+	}
+
+	return dots
+}
+
+func TestInvertCanvasDotCounts(t *testing.T) {
+	pixels := [][]rune{
+		{'⠀', '⣿', '⠁'},
+		{'⡇', '⠀', '⢸'},
+	}
+
+	fileName := writeTestCanvas(t, pixels, 1, 2)
+	before := countDots(readCanvasPixels(fileName, baseReadOptions))
+	imageBefore := readTestImage(t, fileName)
+
+	if err := invertCanvas(fileName, 1, 2, baseReadOptions); err != nil {
+		t.Fatal(err)
+	}
+
+	inverted := readCanvasPixels(fileName, baseReadOptions)
+	allDots := len(pixels) * len(pixels[0]) * convert.BRAILLE_WIDTH * convert.BRAILLE_HEIGHT
+
+	if before != 17 {
+		t.Fatalf("the fixture has %v dots, want 17", before)
+	}
+
+	if after := countDots(inverted); after != allDots-before {
+		t.Errorf("inverted to %v dots, want %v", after, allDots-before)
+	}
+
+	want := [][]rune{
+		{'⣿', '⠀', '⣾'},
+		{'⢸', '⣿', '⡇'},
+	}
+
+	if !slices.EqualFunc(inverted, want, slices.Equal) {
+		t.Errorf("inverted to %q, want %q", inverted, want)
+	}
+
+	m, err := getCanvasMeasurement(fileName, 1, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dotPixels := map[image.Point]bool{}
+	for dotY := range m.CharsY * m.CellH {
+		for dotX := range m.CharsX * convert.BRAILLE_WIDTH {
+			x, y := m.DotPixel(dotX, dotY)
+			dotPixels[image.Pt(x, y)] = true
+		}
+	}
+
+	// The padding stays as it was.
+	imageAfter := readTestImage(t, fileName)
+	for y := range m.ImageHeight {
+		for x := range m.ImageWidth {
+			if !dotPixels[image.Pt(x, y)] && imageBefore.NRGBAAt(x, y) != imageAfter.NRGBAAt(x, y) {
+				t.Fatalf("the padding pixel at %v,%v changed", x, y)
+			}
+		}
+	}
+}
+
+func TestFloodFillEnclosedRegion(t *testing.T) {
+	pixels := [][]rune{
+		[]rune("⠀⠀⠀⠀⠀⠀"),
+		[]rune("⠀⣿⣿⣿⣿⠀"),
+		[]rune("⠀⣿⠀⠀⣿⠀"),
+		[]rune("⠀⣿⣿⣿⣿⠀"),
+		[]rune("⠀⠀⠀⠀⠀⠀"),
+	}
+
+	fileName := writeTestCanvas(t, pixels, 0, 2)
+
+	cells := floodFillCells(pixels, image.Pt(2, 2), convert.BRAILLE_HEIGHT)
+	if want := []image.Point{{2, 2}, {3, 2}}; !slices.Equal(cells, want) {
+		t.Fatalf("filling inside the box reaches %v, want %v", cells, want)
+	}
+
+	if err := fillCells(fileName, 0, 2, cells, true, symmetryNone); err != nil {
+		t.Fatal(err)
+	}
+
+	want := [][]rune{
+		[]rune("⠀⠀⠀⠀⠀⠀"),
+		[]rune("⠀⣿⣿⣿⣿⠀"),
+		[]rune("⠀⣿⣿⣿⣿⠀"),
+		[]rune("⠀⣿⣿⣿⣿⠀"),
+		[]rune("⠀⠀⠀⠀⠀⠀"),
+	}
+
+	if filled := readCanvasPixels(fileName, baseReadOptions); !slices.EqualFunc(filled, want, slices.Equal) {
+		t.Errorf("filled to %q, want %q", filled, want)
+	}
+
+	// From outside, the fill goes around the box without getting in.
+	if outside := floodFillCells(pixels, image.Pt(0, 0), convert.BRAILLE_HEIGHT); len(outside) != 18 || slices.Contains(outside, image.Pt(2, 2)) {
+		t.Errorf("filling outside the box reaches %v, want the 18 characters around it", outside)
+	}
+}
+
+func TestBackupMatchesThePreEditBytes(t *testing.T) {
+	keepBackups = true
+	t.Cleanup(func() { keepBackups = false })
+
+	// Cleaning a canvas that is already clean rewrites it as it was.
+	edits := []struct {
+		name    string
+		changes bool
+		edit    func(fileName string) error
+	}{
+		{"clean", false, func(fileName string) error { return cleanCanvas(fileName, 0, 2, true, baseReadOptions) }},
+		{"toggle padding", true, func(fileName string) error { return togglePaddingState(fileName, 0, 2) }},
+		{"invert", true, func(fileName string) error { return invertCanvas(fileName, 0, 2, baseReadOptions) }},
+		{"resize", true, func(fileName string) error { return resizeCanvas(fileName, 0, 2, 1, 1, image.Point{}) }},
+		{"toggle dot", true, func(fileName string) error {
+			return toggleDot(fileName, 0, 2, image.Pt(1, 1), 3, symmetryNone, baseReadOptions)
+		}},
+	}
+
+	for _, test := range edits {
+		t.Run(test.name, func(t *testing.T) {
+			fileName := writeTestCanvas(t, testPixels(3, 2), 0, 2)
+
+			// The backup is of the last change, so a second edit replaces it.
+			for range 2 {
+				before, err := os.ReadFile(fileName)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if err := test.edit(fileName); err != nil {
+					t.Fatal(err)
+				}
+
+				ageTestFile(t, fileName)
+
+				backup, err := os.ReadFile(fileName + ".bak")
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if !bytes.Equal(backup, before) {
+					t.Error("the backup differs from the file before the edit")
+				}
+
+				if after, _ := os.ReadFile(fileName); test.changes && bytes.Equal(after, before) {
+					t.Error("the edit left the file as it was")
+				}
+			}
+		})
+	}
+}
+
+func TestNoBackupByDefault(t *testing.T) {
+	fileName := writeTestCanvas(t, testPixels(3, 2), 0, 2)
+
+	if err := invertCanvas(fileName, 0, 2, baseReadOptions); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := os.Stat(fileName + ".bak"); !os.IsNotExist(err) {
+		t.Errorf("a backup was kept without --backup, %v", err)
+	}
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// Keys of the config file. Every key is optional, and flags override them.
+type bendayConfig struct {
+	CharsX        int    `json:"charsX"`
+	CharsY        int    `json:"charsY"`
+	PaddingX      *int   `json:"paddingX"`
+	PaddingY      *int   `json:"paddingY"`
+	Ink           string `json:"ink"`
+	WatchInterval string `json:"watchInterval"`
+	MaxPixels     int    `json:"maxPixels"`
+	LastDirectory string `json:"lastDirectory"`
+}
+
+// Initial values of the create and import forms. A size of 0 leaves the input empty.
+var (
+	defaultCharsX   = 0
+	defaultCharsY   = 0
+	defaultPaddingX = 0
+	defaultPaddingY = 2
+	defaultInkSpec  = "333333"
+)
+
+// Where the file pickers start, kept up to date by benday itself. Empty for the working directory.
+var lastDirectory = ""
+
+func configPath() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(configDir, "benday", "config.json"), nil
+}
+
+// A missing config file is not an error. Invalid keys are reported and skipped,
+// keeping the built-in default for that key.
+func loadConfig() []error {
+	path, err := configPath()
+	if err != nil {
+		return nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+
+	if err != nil {
+		return []error{fmt.Errorf("Cannot read the config file \"%v\": %v", path, err)}
+	}
+
+	config := bendayConfig{}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return []error{fmt.Errorf("Ignoring the malformed config file \"%v\": %v", path, err)}
+	}
+
+	warnings := []error{}
+	warn := func(key string, err error) {
+		warnings = append(warnings, fmt.Errorf("Ignoring \"%v\" in the config file: %v", key, err))
+	}
+
+	if config.CharsX != 0 {
+		if err := isWholeNumber(strconv.Itoa(config.CharsX)); err != nil {
+			warn("charsX", err)
+		} else {
+			defaultCharsX = config.CharsX
+		}
+	}
+
+	if config.CharsY != 0 {
+		if err := isWholeNumber(strconv.Itoa(config.CharsY)); err != nil {
+			warn("charsY", err)
+		} else {
+			defaultCharsY = config.CharsY
+		}
+	}
+
+	if config.PaddingX != nil {
+		if err := isValidPadding(strconv.Itoa(*config.PaddingX)); err != nil {
+			warn("paddingX", err)
+		} else {
+			defaultPaddingX = *config.PaddingX
+		}
+	}
+
+	if config.PaddingY != nil {
+		if err := isValidPadding(strconv.Itoa(*config.PaddingY)); err != nil {
+			warn("paddingY", err)
+		} else {
+			defaultPaddingY = *config.PaddingY
+		}
+	}
+
+	if config.Ink != "" {
+		if _, err := parseInkColor(config.Ink); err != nil {
+			warn("ink", fmt.Errorf("Must be a dark color in the form RRGGBB."))
+		} else {
+			defaultInkSpec = config.Ink
+		}
+	}
+
+	if config.WatchInterval != "" {
+		interval, err := time.ParseDuration(config.WatchInterval)
+		if err != nil || interval <= 0 {
+			warn("watchInterval", fmt.Errorf("Must be a positive duration like 250ms."))
+		} else {
+			defaultWatchInterval = interval
+		}
+	}
+
+	if config.MaxPixels != 0 {
+		if config.MaxPixels < 0 {
+			warn("maxPixels", fmt.Errorf("Must be a positive number."))
+		} else {
+			maxCanvasPixels = config.MaxPixels
+		}
+	}
+
+	// Deleted or moved directories fall back to the working directory without a warning.
+	if config.LastDirectory != "" && isDirectory(config.LastDirectory) {
+		lastDirectory = config.LastDirectory
+	}
+
+	return warnings
+}
+
+// Rewrites only the lastDirectory key, keeping the rest of the config file.
+// Malformed config files are left alone, and errors are ignored like for the recent files.
+func saveLastDirectory(dir string) {
+	dir, err := filepath.Abs(dir)
+	if err != nil || dir == lastDirectory {
+		return
+	}
+
+	lastDirectory = dir
+
+	path, err := configPath()
+	if err != nil {
+		return
+	}
+
+	config := map[string]json.RawMessage{}
+
+	data, err := os.ReadFile(path)
+	if err == nil {
+		if err := json.Unmarshal(data, &config); err != nil {
+			return
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return
+	}
+
+	config["lastDirectory"], _ = json.Marshal(dir)
+
+	data, err = json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return
+	}
+
+	os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+func sizeInputValue(chars int) string {
+	if chars == 0 {
+		return ""
+	}
+
+	return strconv.Itoa(chars)
+}
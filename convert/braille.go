@@ -1,7 +1,14 @@
-package main
+package convert
 
 import "strings"
 
+const (
+	BRAILLE_HEIGHT = 4
+	BRAILLE_WIDTH  = 2
+
+	SIX_DOT_BRAILLE_HEIGHT = 3
+)
+
 var brailleCharacters = []string{
 	"⠀", "⠁", "⠈", "⠉", "⠂", "⠃", "⠊", "⠋",
 	"⠐", "⠑", "⠘", "⠙", "⠒", "⠓", "⠚", "⠛",
@@ -52,16 +59,39 @@ var brailleCharacters = []string{
 	"⣴", "⣵", "⣼", "⣽", "⣶", "⣷", "⣾", "⣿",
 }
 
-var brailleLookup = []rune(strings.Join(brailleCharacters, ""))
-
-func BrailleReverseLookup(char rune) int64 {
-	if !isBraille(char) {
-		return 0
-	}
+var BrailleLookup = []rune(strings.Join(brailleCharacters, ""))
 
-	return int64(char - 0x2800)
+// The bits of the bottom dot row come last, so the six-dot characters (U+2800 to U+283F)
+// are the first 64 entries of the lookup.
+func BrailleLookupFor(cellH int) []rune {
+	return BrailleLookup[:1<<(BRAILLE_WIDTH*cellH)]
 }
 
-func isBraille(r rune) bool {
+func IsBraille(r rune) bool {
 	return r >= 0x2800 && r <= 0x28ff
 }
+
+// Bit (y*BRAILLE_WIDTH + x) of a pattern is the dot at column x and row y of the character,
+// the order BrailleLookup is laid out in.
+func DotBit(x int, y int) int {
+	return 1 << (y*BRAILLE_WIDTH + x)
+}
+
+func BitsToRune(bits int) rune {
+	return BrailleLookup[bits]
+}
+
+var brailleBits = func() map[rune]int {
+	bits := make(map[rune]int, len(BrailleLookup))
+	for b, char := range BrailleLookup {
+		bits[char] = b
+	}
+
+	return bits
+}()
+
+// Returns false for characters that are not braille.
+func RuneToBits(char rune) (int, bool) {
+	bits, ok := brailleBits[char]
+	return bits, ok
+}
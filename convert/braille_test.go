@@ -0,0 +1,99 @@
+package convert
+
+import "testing"
+
+// Unicode numbers the dots 1-3 and 7 down the left column, and 4-6 and 8 down the right,
+// as bits 0 to 7 of the code point.
+var unicodeDotBits = [BRAILLE_HEIGHT][BRAILLE_WIDTH]int{
+	{0, 3}, {1, 4}, {2, 5}, {6, 7},
+}
+
+func TestBrailleLookupMatchesUnicode(t *testing.T) {
+	if len(BrailleLookup) != 0x100 {
+		t.Fatalf("BrailleLookup has %v characters, want 256", len(BrailleLookup))
+	}
+
+	for bits := range len(BrailleLookup) {
+		codePoint := rune(0x2800)
+		for y := range BRAILLE_HEIGHT {
+			for x := range BRAILLE_WIDTH {
+				if bits&DotBit(x, y) != 0 {
+					codePoint |= 1 << unicodeDotBits[y][x]
+				}
+			}
+		}
+
+		if char := BitsToRune(bits); char != codePoint {
+			t.Errorf("BitsToRune(%08b) = %q, want %q", bits, char, codePoint)
+		}
+
+		if roundTrip, ok := RuneToBits(codePoint); !ok || roundTrip != bits {
+			t.Errorf("RuneToBits(%q) = %08b, %v, want %08b, true", codePoint, roundTrip, ok, bits)
+		}
+	}
+}
+
+func TestBrailleLookupCoversTheBlock(t *testing.T) {
+	seen := map[rune]bool{}
+	for _, char := range BrailleLookup {
+		if seen[char] {
+			t.Errorf("%q is in BrailleLookup twice", char)
+		}
+
+		seen[char] = true
+	}
+
+	for char := rune(0x2800); char <= 0x28ff; char += 1 {
+		if !seen[char] {
+			t.Errorf("%q is missing from BrailleLookup", char)
+		}
+
+		if !IsBraille(char) {
+			t.Errorf("IsBraille(%q) = false", char)
+		}
+
+		bits, ok := RuneToBits(char)
+		if !ok || BitsToRune(bits) != char {
+			t.Errorf("%q reads back as %q", char, BitsToRune(bits))
+		}
+	}
+}
+
+func TestBrailleLookupForSixDots(t *testing.T) {
+	lookup := BrailleLookupFor(SIX_DOT_BRAILLE_HEIGHT)
+	if len(lookup) != 64 {
+		t.Fatalf("the six-dot lookup has %v characters, want 64", len(lookup))
+	}
+
+	for _, char := range lookup {
+		if char > 0x283f {
+			t.Errorf("%q has dots 7 or 8", char)
+		}
+	}
+}
+
+func TestRuneToBitsRejectsOtherCharacters(t *testing.T) {
+	for _, char := range []rune{' ', 'a', 0x27ff, 0x2900} {
+		if bits, ok := RuneToBits(char); ok {
+			t.Errorf("RuneToBits(%q) = %08b, true, want false", char, bits)
+		}
+
+		if IsBraille(char) {
+			t.Errorf("IsBraille(%q) = true", char)
+		}
+	}
+}
+
+func TestRuneToBitsInvertsBitsToRune(t *testing.T) {
+	for bits := range len(BrailleLookup) {
+		if roundTrip, ok := RuneToBits(BitsToRune(bits)); !ok || roundTrip != bits {
+			t.Errorf("RuneToBits(BitsToRune(%08b)) = %08b, %v", bits, roundTrip, ok)
+		}
+	}
+
+	for _, char := range []rune{' ', 'x', 0x27ff, 0x2900} {
+		if bits, ok := RuneToBits(char); ok {
+			t.Errorf("RuneToBits(%q) = %08b, true, want it refused", char, bits)
+		}
+	}
+}
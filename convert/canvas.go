@@ -0,0 +1,177 @@
+package convert
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+)
+
+type CanvasBackground int
+
+const (
+	BackgroundCheckerboard CanvasBackground = iota
+	BackgroundSolid
+	BackgroundTransparent
+)
+
+func (background CanvasBackground) String() string {
+	switch background {
+	case BackgroundSolid:
+		return "solid"
+	case BackgroundTransparent:
+		return "transparent"
+	default:
+		return "checkerboard"
+	}
+}
+
+// Where the dots sit inside a padded character, on both axes. Unpadded canvases have
+// no room around the dots, so this only matters while padded.
+type DotAlignment int
+
+const (
+	AlignStart DotAlignment = iota
+	AlignCenter
+	AlignEnd
+)
+
+func (align DotAlignment) String() string {
+	switch align {
+	case AlignCenter:
+		return "center"
+	case AlignEnd:
+		return "bottom-right"
+	default:
+		return "top-left"
+	}
+}
+
+// Position of the dots inside a padded character. Centering rounds towards the top left.
+func (align DotAlignment) Offset(paddingX int, paddingY int) image.Point {
+	return image.Pt(paddingX*int(align)/2, paddingY*int(align)/2)
+}
+
+func NewCanvasImage(imageWidth int, imageHeight int, paddingX int, paddingY int, unpadded bool, cellH int, background CanvasBackground, align DotAlignment) draw.Image {
+	whiteImage := image.Uniform{color.NRGBA{0xff, 0xff, 0xff, 0xff}}
+	if background == BackgroundTransparent {
+		whiteImage = image.Uniform{color.NRGBA{}}
+	}
+
+	img := image.NewNRGBA(image.Rect(0, 0, imageWidth, imageHeight))
+	draw.Draw(img, img.Bounds(), &whiteImage, image.Point{}, draw.Src)
+
+	colorGray := color.NRGBA{R: 0xcc, G: 0xcc, B: 0xcc, A: 0xff}
+	paintWhiteStart := true
+
+	braillePaddedW := paddingX + BRAILLE_WIDTH
+	braillePaddedH := paddingY + cellH
+	dotOffset := align.Offset(paddingX, paddingY)
+
+	if unpadded {
+		braillePaddedW = BRAILLE_WIDTH
+		braillePaddedH = cellH
+		dotOffset = image.Point{}
+	}
+
+	// Solid and transparent backgrounds leave out the gray cells.
+	if background == BackgroundCheckerboard {
+		for bigYOff := 0; bigYOff < imageHeight; bigYOff += braillePaddedH {
+			grayPainterOffsetX := 0
+			if paintWhiteStart {
+				grayPainterOffsetX += braillePaddedW
+			}
+
+			for bigXOff := grayPainterOffsetX; bigXOff < imageWidth; bigXOff += 2 * braillePaddedW {
+				for charYOff := 0; charYOff < cellH; charYOff += 1 {
+					for charXOff := 0; charXOff < BRAILLE_WIDTH; charXOff += 1 {
+						x := bigXOff + dotOffset.X + charXOff
+						y := bigYOff + dotOffset.Y + charYOff
+
+						img.SetNRGBA(x, y, colorGray)
+					}
+				}
+			}
+
+			paintWhiteStart = !paintWhiteStart
+		}
+	}
+
+	finalImage := draw.Image(img)
+	if unpadded {
+		transparentImg := image.NewUniform(color.NRGBA{})
+
+		verticalRect := image.Rect(imageWidth-1, 0, imageWidth, imageHeight)
+		horizontalRect := image.Rect(0, imageHeight-1, imageWidth, imageHeight)
+
+		draw.Draw(finalImage, verticalRect, transparentImg, image.Point{}, draw.Src)
+		draw.Draw(finalImage, horizontalRect, transparentImg, image.Point{}, draw.Src)
+	} else {
+		finalImage = DrawPadding(finalImage, paddingX, paddingY, cellH, align)
+	}
+
+	return finalImage
+}
+
+// Clears everything around the dots of every character.
+func DrawPadding(img draw.Image, paddingX int, paddingY int, cellH int, align DotAlignment) draw.Image {
+	braillePaddedW := paddingX + BRAILLE_WIDTH
+	braillePaddedH := paddingY + cellH
+	dotOffset := align.Offset(paddingX, paddingY)
+
+	if paddingX == 0 && paddingY == 0 {
+		return img
+	}
+
+	bounds := img.Bounds()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y += 1 {
+		for x := bounds.Min.X; x < bounds.Max.X; x += 1 {
+			if isDotPixel(x-bounds.Min.X, y-bounds.Min.Y, braillePaddedW, braillePaddedH, cellH, dotOffset) {
+				continue
+			}
+
+			img.Set(x, y, color.NRGBA{})
+		}
+	}
+
+	return img
+}
+
+func isDotPixel(x int, y int, braillePaddedW int, braillePaddedH int, cellH int, dotOffset image.Point) bool {
+	cellX := x%braillePaddedW - dotOffset.X
+	cellY := y%braillePaddedH - dotOffset.Y
+
+	return cellX >= 0 && cellX < BRAILLE_WIDTH && cellY >= 0 && cellY < cellH
+}
+
+// On six-dot canvases, the bottom dots of eight-dot characters are dropped.
+func NewPixelsImage(pixels [][]rune, paddingX int, paddingY int, cellH int, align DotAlignment, ink color.NRGBA) *image.NRGBA {
+	charsX := len(pixels[0])
+	charsY := len(pixels)
+
+	imageWidth := charsX * (paddingX + BRAILLE_WIDTH)
+	imageHeight := charsY * (paddingY + cellH)
+
+	img := NewCanvasImage(imageWidth, imageHeight, paddingX, paddingY, false, cellH, BackgroundCheckerboard, align).(*image.NRGBA)
+	dotOffset := align.Offset(paddingX, paddingY)
+
+	for charY, _line := range pixels {
+		for charX, charRune := range _line {
+			brailleIdx, _ := RuneToBits(charRune)
+
+			for brailleYOff := range cellH {
+				for brailleXOff := range BRAILLE_WIDTH {
+					if brailleIdx&DotBit(brailleXOff, brailleYOff) == 0 {
+						continue
+					}
+
+					x := charX*(BRAILLE_WIDTH+paddingX) + dotOffset.X + brailleXOff
+					y := charY*(cellH+paddingY) + dotOffset.Y + brailleYOff
+
+					img.SetNRGBA(x, y, ink)
+				}
+			}
+		}
+	}
+
+	return img
+}
@@ -0,0 +1,91 @@
+// Package convert turns benday canvases into braille characters and back, without the
+// terminal interface of the benday command.
+//
+// Decode and Encode take Options for six-dot canvases, dot alignment, and how pixels are
+// read. The rest of the package exposes the pieces they are built from.
+package convert
+
+import (
+	"errors"
+	"fmt"
+	"image"
+)
+
+var (
+	NegativePaddingError = errors.New("Padding cannot be negative.")
+	NoPixelsError        = errors.New("There are no braille characters to encode.")
+	RaggedPixelsError    = errors.New("Every row must have the same number of braille characters.")
+)
+
+// How Decode and Encode lay out and read a canvas. Start from DefaultOptions, for
+// eight-dot canvases with the dots at the top left of each character.
+type Options struct {
+	ReadOptions
+
+	SixDot bool
+	Align  DotAlignment
+}
+
+func DefaultOptions() Options {
+	return Options{ReadOptions: DefaultReadOptions()}
+}
+
+func (opts Options) cellH() int {
+	if opts.SixDot {
+		return SIX_DOT_BRAILLE_HEIGHT
+	}
+
+	return BRAILLE_HEIGHT
+}
+
+type NotBrailleE struct {
+	charX int
+	charY int
+	char  rune
+}
+
+func (err NotBrailleE) Error() string {
+	return fmt.Sprintf("%q at row %v, column %v is not a braille character.", err.char, err.charY+1, err.charX+1)
+}
+
+// Reads the braille characters of a canvas image, one slice of runes per row.
+// Images with a transparent right column and bottom row are read as unpadded canvases.
+func Decode(img image.Image, paddingX int, paddingY int, opts Options) ([][]rune, error) {
+	if paddingX < 0 || paddingY < 0 {
+		return nil, NegativePaddingError
+	}
+
+	m, err := MeasureCanvasImage(img, paddingX, paddingY, opts.cellH(), opts.Align, opts.ReadOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	return SamplePixels(img, m, opts.ReadOptions), nil
+}
+
+// Draws braille characters onto a padded canvas image with the checkerboard background,
+// as benday does when importing braille text. Blank characters are left unshaded, and dots
+// are written with the ink of the options.
+func Encode(pixels [][]rune, paddingX int, paddingY int, opts Options) (image.Image, error) {
+	if paddingX < 0 || paddingY < 0 {
+		return nil, NegativePaddingError
+	}
+
+	if len(pixels) == 0 || len(pixels[0]) == 0 {
+		return nil, NoPixelsError
+	}
+
+	for charY, line := range pixels {
+		if len(line) != len(pixels[0]) {
+			return nil, RaggedPixelsError
+		}
+
+		for charX, char := range line {
+			if !IsBraille(char) {
+				return nil, NotBrailleE{charX, charY, char}
+			}
+		}
+	}
+
+	return NewPixelsImage(pixels, paddingX, paddingY, opts.cellH(), opts.Align, opts.Ink), nil
+}
@@ -0,0 +1,89 @@
+package convert
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"slices"
+	"testing"
+)
+
+// Every character differs from its neighbours, so moved or dropped dots show up.
+func testPixels(charsX int, charsY int, lookup []rune) [][]rune {
+	pixels := make([][]rune, charsY)
+	for y := range charsY {
+		pixels[y] = make([]rune, charsX)
+		for x := range charsX {
+			pixels[y][x] = lookup[(x*31+y*17+5)%len(lookup)]
+		}
+	}
+
+	return pixels
+}
+
+func TestEncodeDecodeRoundTrip(t *testing.T) {
+	paddings := []image.Point{{0, 0}, {0, 2}, {2, 0}, {1, 3}, {3, 3}}
+
+	sixDot := DefaultOptions()
+	sixDot.SixDot = true
+
+	centered := DefaultOptions()
+	centered.Align = AlignCenter
+
+	red := DefaultOptions()
+	red.Ink = color.NRGBA{0xff, 0, 0, 0xff}
+
+	tests := []struct {
+		name string
+		opts Options
+	}{
+		{"eight-dot", DefaultOptions()},
+		{"six-dot", sixDot},
+		{"centered", centered},
+		{"red ink", red},
+	}
+
+	for _, test := range tests {
+		for _, padding := range paddings {
+			t.Run(fmt.Sprintf("%v at %vx%v padding", test.name, padding.X, padding.Y), func(t *testing.T) {
+				pixels := testPixels(5, 3, BrailleLookupFor(test.opts.cellH()))
+
+				img, err := Encode(pixels, padding.X, padding.Y, test.opts)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				decoded, err := Decode(img, padding.X, padding.Y, test.opts)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if !slices.EqualFunc(pixels, decoded, slices.Equal) {
+					t.Errorf("decoded %q, want %q", decoded, pixels)
+				}
+			})
+		}
+	}
+}
+
+func TestEncodeErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		pixels   [][]rune
+		paddingX int
+		want     error
+	}{
+		{"negative padding", [][]rune{{'⠁'}}, -1, NegativePaddingError},
+		{"no pixels", [][]rune{}, 0, NoPixelsError},
+		{"ragged", [][]rune{{'⠁', '⠂'}, {'⠁'}}, 0, RaggedPixelsError},
+		{"not braille", [][]rune{{'⠁', 'x'}}, 0, NotBrailleE{1, 0, 'x'}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := Encode(test.pixels, test.paddingX, 0, DefaultOptions()); err != test.want {
+				t.Errorf("got %v, want %v", err, test.want)
+			}
+		})
+	}
+}
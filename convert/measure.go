@@ -0,0 +1,191 @@
+package convert
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+)
+
+type InvalidImgDimensionE struct {
+	measure           int
+	mustBeDivisibleBy int
+	errorOnX          bool
+
+	isUnpadded bool
+}
+
+func (err InvalidImgDimensionE) Error() string {
+	measureName := "width"
+	if !err.errorOnX {
+		measureName = "height"
+	}
+
+	minusOneText := ""
+	if err.isUnpadded {
+		minusOneText = " - 1"
+	}
+
+	return fmt.Sprintf(
+		"Invalid image dimension. Expected %v%v to be divisible by %v, but is instead %v px.",
+		measureName,
+		minusOneText,
+		err.mustBeDivisibleBy,
+		err.measure,
+	)
+}
+
+// Where the characters and dots of a canvas are in its image.
+type CanvasMeasure struct {
+	ImageWidth  int
+	ImageHeight int
+	IsUnpadded  bool
+
+	CharsX int
+	CharsY int
+
+	BrailleW int
+	BrailleH int
+
+	// Dot rows per character, BRAILLE_HEIGHT or SIX_DOT_BRAILLE_HEIGHT.
+	CellH int
+
+	// Painted under the dots by NewCanvasImage, read from the background chunk.
+	Background CanvasBackground
+
+	// Where the dots sit inside padded characters, read from the align chunk.
+	Align DotAlignment
+
+	// The color of shaded dots, read from the ink chunk.
+	Ink color.NRGBA
+}
+
+// Position of a dot in the image, with dots numbered across the whole canvas.
+func (m CanvasMeasure) DotPixel(dotX int, dotY int) (int, int) {
+	dotOffset := m.DotOffset()
+
+	x := (dotX/BRAILLE_WIDTH)*m.BrailleW + dotOffset.X + dotX%BRAILLE_WIDTH
+	y := (dotY/m.CellH)*m.BrailleH + dotOffset.Y + dotY%m.CellH
+
+	return x, y
+}
+
+func (m CanvasMeasure) DotOffset() image.Point {
+	if m.IsUnpadded {
+		return image.Point{}
+	}
+
+	return m.Align.Offset(m.BrailleW-BRAILLE_WIDTH, m.BrailleH-m.CellH)
+}
+
+func MeasureCanvasImage(img image.Image, paddingX int, paddingY int, cellH int, align DotAlignment, opts ReadOptions) (CanvasMeasure, error) {
+	hasTransparentPadding := func() (bool, error) {
+		return IsPaddingTransparent(img, paddingX, paddingY, cellH, align, opts), nil
+	}
+
+	bounds := img.Bounds()
+	m, err := MeasureCanvas(bounds.Dx(), bounds.Dy(), paddingX, paddingY, cellH, hasTransparentPadding)
+	m.Align = align
+	m.Ink = opts.Ink
+
+	return m, err
+}
+
+func MeasureCanvas(
+	imageWidth int,
+	imageHeight int,
+	paddingX int,
+	paddingY int,
+	cellH int,
+	hasTransparentPadding func() (bool, error),
+) (CanvasMeasure, error) {
+	imageTestWidth := imageWidth
+	imageTestHeight := imageHeight
+
+	brailleW := BRAILLE_WIDTH + paddingX
+	brailleH := cellH + paddingY
+
+	padded := imageTestWidth%brailleW == 0 && imageTestHeight%brailleH == 0
+
+	// Some unpadded canvases (e.g. 1x5 chars at 1x3 padding) are also divisible by the
+	// padded cell size. Those are told apart by the padding area being left transparent.
+	fitsUnpadded := (imageTestWidth-1)%BRAILLE_WIDTH == 0 && (imageTestHeight-1)%cellH == 0
+	if padded && fitsUnpadded {
+		var err error
+
+		padded, err = hasTransparentPadding()
+		if err != nil {
+			return CanvasMeasure{}, err
+		}
+	}
+
+	// Canvases that fit neither way are measured the way more of their sides fit, padded
+	// on a tie, so an image off by a row is reported for its height.
+	paddedSides := countTrue(imageTestWidth%brailleW == 0, imageTestHeight%brailleH == 0)
+	unpaddedSides := countTrue((imageTestWidth-1)%BRAILLE_WIDTH == 0, (imageTestHeight-1)%cellH == 0)
+
+	unpadded := !padded && (fitsUnpadded || unpaddedSides > paddedSides)
+	if unpadded {
+		brailleW = BRAILLE_WIDTH
+		brailleH = cellH
+
+		imageTestWidth -= 1
+		imageTestHeight -= 1
+	}
+
+	charsX := imageTestWidth / brailleW
+	charsY := imageTestHeight / brailleH
+
+	if charsX*brailleW != imageTestWidth {
+		err := InvalidImgDimensionE{imageWidth, brailleW, true, unpadded}
+		return CanvasMeasure{}, err
+	}
+
+	if charsY*brailleH != imageTestHeight {
+		err := InvalidImgDimensionE{imageHeight, brailleH, false, unpadded}
+		return CanvasMeasure{}, err
+	}
+
+	measurements := CanvasMeasure{
+		ImageWidth:  imageWidth,
+		ImageHeight: imageHeight,
+		IsUnpadded:  unpadded,
+		CharsX:      charsX,
+		CharsY:      charsY,
+		BrailleW:    brailleW,
+		BrailleH:    brailleH,
+		CellH:       cellH,
+	}
+	return measurements, nil
+}
+
+func countTrue(conditions ...bool) int {
+	count := 0
+	for _, condition := range conditions {
+		if condition {
+			count += 1
+		}
+	}
+
+	return count
+}
+
+func IsPaddingTransparent(img image.Image, paddingX int, paddingY int, cellH int, align DotAlignment, opts ReadOptions) bool {
+	brailleW := BRAILLE_WIDTH + paddingX
+	brailleH := cellH + paddingY
+	dotOffset := align.Offset(paddingX, paddingY)
+
+	bounds := img.Bounds()
+	for y := range bounds.Dy() {
+		for x := range bounds.Dx() {
+			if isDotPixel(x, y, brailleW, brailleH, cellH, dotOffset) {
+				continue
+			}
+
+			if ShadeTypeAt(img.At(bounds.Min.X+x, bounds.Min.Y+y), opts) != ColorTransparent {
+				return false
+			}
+		}
+	}
+
+	return true
+}
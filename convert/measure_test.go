@@ -0,0 +1,42 @@
+package convert
+
+import "testing"
+
+func TestMeasureCanvasDimensionErrors(t *testing.T) {
+	noPadding := func() (bool, error) { return false, nil }
+
+	tests := []struct {
+		name     string
+		width    int
+		height   int
+		paddingX int
+		paddingY int
+		want     InvalidImgDimensionE
+	}{
+		// 3x2 characters of 2x6 pixels.
+		{"padded height off by one", 6, 13, 0, 2, InvalidImgDimensionE{13, 6, false, false}},
+		{"padded width off by one", 7, 12, 1, 0, InvalidImgDimensionE{7, 3, true, false}},
+
+		// 3x2 characters of 2x4 pixels, and the extra column and row.
+		{"unpadded height off by one", 7, 10, 0, 2, InvalidImgDimensionE{10, 4, false, true}},
+		{"unpadded width off by one", 8, 9, 1, 1, InvalidImgDimensionE{8, 2, true, true}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := MeasureCanvas(test.width, test.height, test.paddingX, test.paddingY, BRAILLE_HEIGHT, noPadding)
+			if err != test.want {
+				t.Errorf("got %v, want %v", err, test.want)
+			}
+		})
+	}
+}
+
+func TestInvalidImgDimensionMessage(t *testing.T) {
+	err := InvalidImgDimensionE{13, 6, false, false}
+	want := "Invalid image dimension. Expected height to be divisible by 6, but is instead 13 px."
+
+	if err.Error() != want {
+		t.Errorf("got %q, want %q", err.Error(), want)
+	}
+}
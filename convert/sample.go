@@ -0,0 +1,108 @@
+package convert
+
+import (
+	"image"
+	"image/color"
+	"runtime"
+	"sync"
+)
+
+func SamplePixels(img image.Image, m CanvasMeasure, opts ReadOptions) [][]rune {
+	pixels := make([][]rune, m.CharsY)
+	for y := range pixels {
+		pixels[y] = make([]rune, m.CharsX)
+	}
+
+	bounds := img.Bounds()
+	origin := bounds.Min
+	dotOffset := m.DotOffset()
+
+	sampleRow := func(charY int) {
+		for charX := range m.CharsX {
+			brailleIdx := 0
+
+			for charYOff := range m.CellH {
+				for charXOff := range BRAILLE_WIDTH {
+					x := origin.X + charX*m.BrailleW + dotOffset.X + charXOff
+					y := origin.Y + charY*m.BrailleH + dotOffset.Y + charYOff
+
+					if image.Pt(x, y).In(bounds) && ShadeTypeAt(img.At(x, y), opts) == ColorShaded {
+						brailleIdx |= DotBit(charXOff, charYOff)
+					}
+				}
+			}
+
+			pixels[charY][charX] = BitsToRune(brailleIdx)
+		}
+	}
+
+	// Rows are independent, so each worker fills every workerCount-th row without locking.
+	workerCount := min(runtime.NumCPU(), m.CharsY)
+	waitGroup := sync.WaitGroup{}
+
+	for worker := range workerCount {
+		waitGroup.Add(1)
+
+		go func() {
+			defer waitGroup.Done()
+
+			for charY := worker; charY < m.CharsY; charY += workerCount {
+				sampleRow(charY)
+			}
+		}()
+	}
+
+	waitGroup.Wait()
+	return pixels
+}
+
+// The color of a character is the average of its shaded and colored dots,
+// or nil when the character has none.
+func SampleColors(img image.Image, m CanvasMeasure, opts ReadOptions) [][]color.Color {
+	colors := make([][]color.Color, m.CharsY)
+	for y := range colors {
+		colors[y] = make([]color.Color, m.CharsX)
+	}
+
+	bounds := img.Bounds()
+	origin := bounds.Min
+	dotOffset := m.DotOffset()
+
+	for charY := range m.CharsY {
+		for charX := range m.CharsX {
+			var sumR, sumG, sumB, count uint32
+
+			for charYOff := range m.CellH {
+				for charXOff := range BRAILLE_WIDTH {
+					x := origin.X + charX*m.BrailleW + dotOffset.X + charXOff
+					y := origin.Y + charY*m.BrailleH + dotOffset.Y + charYOff
+
+					if !image.Pt(x, y).In(bounds) {
+						continue
+					}
+
+					pxColor := img.At(x, y)
+					if shade := ShadeTypeAt(pxColor, opts); shade != ColorShaded && shade != ColorNonGrayscale {
+						continue
+					}
+
+					nrgba := color.NRGBAModel.Convert(pxColor).(color.NRGBA)
+					sumR += uint32(nrgba.R)
+					sumG += uint32(nrgba.G)
+					sumB += uint32(nrgba.B)
+					count += 1
+				}
+			}
+
+			if count == 0 {
+				continue
+			}
+
+			colors[charY][charX] = color.NRGBA{
+				uint8(sumR / count), uint8(sumG / count), uint8(sumB / count), 0xff,
+			}
+		}
+	}
+
+	return colors
+}
@@ -0,0 +1,146 @@
+package convert
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"slices"
+	"testing"
+)
+
+// The rows one by one, with each dot found through DotPixel.
+func samplePixelsSerially(img image.Image, m CanvasMeasure, opts ReadOptions) [][]rune {
+	bounds := img.Bounds()
+	pixels := make([][]rune, m.CharsY)
+
+	for charY := range m.CharsY {
+		pixels[charY] = make([]rune, m.CharsX)
+
+		for charX := range m.CharsX {
+			brailleIdx := 0
+
+			for dotY := range m.CellH {
+				for dotX := range BRAILLE_WIDTH {
+					x, y := m.DotPixel(charX*BRAILLE_WIDTH+dotX, charY*m.CellH+dotY)
+					pt := bounds.Min.Add(image.Pt(x, y))
+
+					if pt.In(bounds) && ShadeTypeAt(img.At(pt.X, pt.Y), opts) == ColorShaded {
+						brailleIdx |= DotBit(dotX, dotY)
+					}
+				}
+			}
+
+			pixels[charY][charX] = BitsToRune(brailleIdx)
+		}
+	}
+
+	return pixels
+}
+
+func encodeTestCanvas(tb testing.TB, pixels [][]rune, paddingX int, paddingY int, opts Options) (image.Image, CanvasMeasure) {
+	tb.Helper()
+
+	img, err := Encode(pixels, paddingX, paddingY, opts)
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	m, err := MeasureCanvasImage(img, paddingX, paddingY, opts.cellH(), opts.Align, opts.ReadOptions)
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	return img, m
+}
+
+func TestSamplePixelsMatchesSerialSampling(t *testing.T) {
+	sixDot := DefaultOptions()
+	sixDot.SixDot = true
+
+	centered := DefaultOptions()
+	centered.Align = AlignCenter
+
+	tests := []struct {
+		name     string
+		opts     Options
+		paddingX int
+		paddingY int
+	}{
+		{"eight-dot", DefaultOptions(), 0, 2},
+		{"six-dot", sixDot, 1, 1},
+		{"centered", centered, 3, 3},
+		{"unpadded", DefaultOptions(), 0, 0},
+	}
+
+	// More rows than workers, and a row count that does not split evenly between them.
+	for _, size := range []image.Point{{1, 1}, {7, 3}, {13, 97}} {
+		for _, test := range tests {
+			t.Run(fmt.Sprintf("%v %vx%v", test.name, size.X, size.Y), func(t *testing.T) {
+				pixels := testPixels(size.X, size.Y, BrailleLookupFor(test.opts.cellH()))
+				img, m := encodeTestCanvas(t, pixels, test.paddingX, test.paddingY, test.opts)
+
+				got := SamplePixels(img, m, test.opts.ReadOptions)
+				want := samplePixelsSerially(img, m, test.opts.ReadOptions)
+
+				if !slices.EqualFunc(got, want, slices.Equal) {
+					t.Errorf("sampled %q, want %q", got, want)
+				}
+			})
+		}
+	}
+}
+
+func TestSamplePixelsOffsetBounds(t *testing.T) {
+	pixels := testPixels(6, 4, BrailleLookup)
+	img, _ := encodeTestCanvas(t, pixels, 0, 2, DefaultOptions())
+
+	// A sub-image does not start at the origin, the first character starts at its corner.
+	cropped := img.(*image.NRGBA).SubImage(image.Rect(2, 6, 12, 24))
+
+	m, err := MeasureCanvasImage(cropped, 0, 2, BRAILLE_HEIGHT, AlignStart, DefaultReadOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := [][]rune{}
+	for _, line := range pixels[1:4] {
+		want = append(want, line[1:6])
+	}
+
+	got := SamplePixels(cropped, m, DefaultReadOptions())
+	if !slices.EqualFunc(got, want, slices.Equal) {
+		t.Errorf("sampled %q, want %q", got, want)
+	}
+
+	if serial := samplePixelsSerially(cropped, m, DefaultReadOptions()); !slices.EqualFunc(got, serial, slices.Equal) {
+		t.Errorf("sampled %q, serially %q", got, serial)
+	}
+}
+
+func TestSamplePixelsInk(t *testing.T) {
+	opts := DefaultOptions()
+	opts.Ink = color.NRGBA{0xff, 0, 0, 0xff}
+
+	pixels := testPixels(5, 3, BrailleLookup)
+	img, m := encodeTestCanvas(t, pixels, 1, 2, opts)
+
+	if got := SamplePixels(img, m, opts.ReadOptions); !slices.EqualFunc(got, pixels, slices.Equal) {
+		t.Errorf("sampled %q with the red ink, want %q", got, pixels)
+	}
+
+	// Read with another ink, the red dots are colored comments.
+	blank := testPixels(5, 3, BrailleLookup[:1])
+	if got := SamplePixels(img, m, DefaultReadOptions()); !slices.EqualFunc(got, blank, slices.Equal) {
+		t.Errorf("sampled %q with the default ink, want %q", got, blank)
+	}
+}
+
+func BenchmarkSamplePixels(b *testing.B) {
+	pixels := testPixels(200, 200, BrailleLookup)
+	img, m := encodeTestCanvas(b, pixels, 0, 2, DefaultOptions())
+
+	b.ResetTimer()
+	for range b.N {
+		SamplePixels(img, m, DefaultReadOptions())
+	}
+}
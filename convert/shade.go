@@ -0,0 +1,112 @@
+package convert
+
+import (
+	"fmt"
+	"image/color"
+)
+
+// How a pixel is read when sampling a canvas. Only shaded pixels become dots.
+type ShadedType int
+
+const (
+	ColorTransparent ShadedType = iota
+	ColorNonGrayscale
+	ColorNonShaded
+	ColorShaded
+)
+
+// Brightness below which a grayscale color is shaded, in twelfths of full brightness.
+type ShadeThreshold uint32
+
+const (
+	DefaultShadeThreshold ShadeThreshold = 8
+	MinShadeThreshold     ShadeThreshold = 1
+	MaxShadeThreshold     ShadeThreshold = 11
+)
+
+func (threshold ShadeThreshold) String() string {
+	return fmt.Sprintf("%v/12", uint32(threshold))
+}
+
+// A third of fully opaque.
+const DefaultMinOpaqueAlpha uint32 = 0x55
+
+// How pixels are read when sampling a canvas. Start from DefaultReadOptions, as the zero
+// value shades nothing.
+type ReadOptions struct {
+	Threshold ShadeThreshold
+
+	// Pixels with less alpha than this are transparent.
+	MinOpaqueAlpha uint32
+
+	// Reads light dots on a dark background as shaded instead, for art drawn light on dark.
+	// Only reading changes, dots are still written with the ink on the canvas background.
+	Invert bool
+
+	// Decides shading by Rec. 709 luminance instead of the plain sum of the channels, so colored
+	// pixels are shaded by how dark they look instead of being left out as comments.
+	Luma bool
+
+	// The color of written dots, read as shaded even though a colored ink is not grayscale.
+	// Not when reading light on dark, where the ink is read by how dark it is like any color.
+	Ink color.NRGBA
+}
+
+// Dark gray, so the dots also read as shaded by tools that know nothing of the ink.
+var DefaultInk = color.NRGBA{0x33, 0x33, 0x33, 0xff}
+
+func DefaultReadOptions() ReadOptions {
+	return ReadOptions{
+		Threshold:      DefaultShadeThreshold,
+		MinOpaqueAlpha: DefaultMinOpaqueAlpha,
+		Ink:            DefaultInk,
+	}
+}
+
+// This ignores sufficiently translucent, non-grayscale (unless reading luminance), and light colors
+// (dark ones when inverted).
+func ShadeTypeAt(c color.Color, opts ReadOptions) ShadedType {
+	pxColor := color.NRGBAModel.Convert(c).(color.NRGBA)
+	r, g, b, a := uint32(pxColor.R), uint32(pxColor.G), uint32(pxColor.B), uint32(pxColor.A)
+
+	if a < opts.MinOpaqueAlpha {
+		return ColorTransparent
+	}
+
+	if pxColor == opts.Ink && !opts.Invert {
+		return ColorShaded
+	}
+
+	threshold := uint32(opts.Threshold)
+
+	// Luminance weights in ten-thousandths, against the same threshold in twelfths of full brightness.
+	if opts.Luma {
+		luminance := 2126*r + 7152*g + 722*b
+		if (12*luminance < 10000*threshold*a) != opts.Invert {
+			return ColorShaded
+		} else {
+			return ColorNonShaded
+		}
+	}
+
+	// Derivation of "deviation":
+	// deviation = (abs(r, g) + abs(g, b) + abs(r, b)) / 3
+	// deviation = (r-g + g-b + r-b) / 3       (without loss of generality: r >= g >= b)
+	// deviation = 2 * (r-b) / 3
+	// deviation = 2 * (maximum(r, g, b) - minimum(r, g, b)) / 3
+	// (then multiplied the divisor to the other side)
+
+	// Originally as:
+	// `if deviation := (abs(r - g) + abs(g - b) + abs(r - b)) / 3; deviation > 0xff/16 { ... }`
+	if deviation := 2 * (max(r, g, b) - min(r, g, b)); 16*deviation > 3*0xff {
+		return ColorNonGrayscale
+	}
+
+	// 3 color channels * threshold/12 brightness = threshold/4 multiplier to alpha
+	sumOfColors := r + g + b
+	if (4*sumOfColors < threshold*a) != opts.Invert {
+		return ColorShaded
+	} else {
+		return ColorNonShaded
+	}
+}
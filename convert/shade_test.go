@@ -0,0 +1,101 @@
+package convert
+
+import (
+	"image/color"
+	"testing"
+)
+
+func TestShadeTypeAtLuma(t *testing.T) {
+	luma := DefaultReadOptions()
+	luma.Luma = true
+
+	lumaInverted := luma
+	lumaInverted.Invert = true
+
+	tests := []struct {
+		name  string
+		color color.NRGBA
+		opts  ReadOptions
+		want  ShadedType
+	}{
+		{"red by sum", color.NRGBA{0xff, 0, 0, 0xff}, DefaultReadOptions(), ColorNonGrayscale},
+		{"green by sum", color.NRGBA{0, 0xff, 0, 0xff}, DefaultReadOptions(), ColorNonGrayscale},
+		{"blue by sum", color.NRGBA{0, 0, 0xff, 0xff}, DefaultReadOptions(), ColorNonGrayscale},
+
+		// Luminance of 0.21, 0.72 and 0.07, against the default threshold of 8/12.
+		{"red by luma", color.NRGBA{0xff, 0, 0, 0xff}, luma, ColorShaded},
+		{"green by luma", color.NRGBA{0, 0xff, 0, 0xff}, luma, ColorNonShaded},
+		{"blue by luma", color.NRGBA{0, 0, 0xff, 0xff}, luma, ColorShaded},
+		{"dark blue by luma", color.NRGBA{0, 0, 0x80, 0xff}, luma, ColorShaded},
+		{"white by luma", color.NRGBA{0xff, 0xff, 0xff, 0xff}, luma, ColorNonShaded},
+		{"black by luma", color.NRGBA{0, 0, 0, 0xff}, luma, ColorShaded},
+
+		{"blue by inverted luma", color.NRGBA{0, 0, 0xff, 0xff}, lumaInverted, ColorNonShaded},
+		{"green by inverted luma", color.NRGBA{0, 0xff, 0, 0xff}, lumaInverted, ColorShaded},
+
+		{"translucent blue by luma", color.NRGBA{0, 0, 0xff, uint8(DefaultMinOpaqueAlpha - 1)}, luma, ColorTransparent},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := ShadeTypeAt(test.color, test.opts); got != test.want {
+				t.Errorf("ShadeTypeAt(%v) = %v, want %v", test.color, got, test.want)
+			}
+		})
+	}
+}
+
+func TestShadeTypeAtInk(t *testing.T) {
+	opts := DefaultReadOptions()
+	opts.Ink = color.NRGBA{0xff, 0, 0, 0xff}
+
+	if got := ShadeTypeAt(opts.Ink, opts); got != ColorShaded {
+		t.Errorf("a red ink read as %v, want it shaded", got)
+	}
+
+	// Read light on dark, the ink is as dark as it looks, so a dark gray ink is blank.
+	opts.Invert = true
+	if got := ShadeTypeAt(opts.Ink, opts); got != ColorNonGrayscale {
+		t.Errorf("a red ink read as %v when inverted, want it non-grayscale", got)
+	}
+
+	inverted := DefaultReadOptions()
+	inverted.Invert = true
+
+	if got := ShadeTypeAt(inverted.Ink, inverted); got != ColorNonShaded {
+		t.Errorf("the default ink read as %v when inverted, want it non-shaded", got)
+	}
+}
+
+func TestShadeTypeAtAlphaBoundary(t *testing.T) {
+	for _, minAlpha := range []uint32{1, DefaultMinOpaqueAlpha, 0xff} {
+		opts := DefaultReadOptions()
+		opts.MinOpaqueAlpha = minAlpha
+
+		tests := []struct {
+			alpha uint32
+			want  ShadedType
+		}{
+			{minAlpha - 1, ColorTransparent},
+			{minAlpha, ColorShaded},
+			{0xff, ColorShaded},
+		}
+
+		for _, test := range tests {
+			black := color.NRGBA{0, 0, 0, uint8(test.alpha)}
+			if got := ShadeTypeAt(black, opts); got != test.want {
+				t.Errorf("black at alpha %#x with a minimum of %#x read as %v, want %v", test.alpha, minAlpha, got, test.want)
+			}
+		}
+	}
+}
+
+func TestDefaultMinOpaqueAlpha(t *testing.T) {
+	// A third of fully opaque, the cutoff from before it could be changed.
+	for alpha := range uint32(0x100) {
+		transparent := ShadeTypeAt(color.NRGBA{0, 0, 0, uint8(alpha)}, DefaultReadOptions()) == ColorTransparent
+		if transparent != (3*alpha < 0xff) {
+			t.Errorf("black at alpha %#x is transparent: %v, want %v", alpha, transparent, 3*alpha < 0xff)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"image"
+	"image/color"
+
+	"github.com/noAbbreviation/benday/convert"
+)
+
+// Floyd-Steinberg error diffusion at dot resolution, so every pixel of the source image
+// becomes one braille dot. Translucent pixels stay blank and do not spread any error.
+func ditherToPixels(img image.Image, cellH int) [][]rune {
+	bounds := img.Bounds()
+	dotsW, dotsH := bounds.Dx(), bounds.Dy()
+
+	brightness := make([][]float64, dotsH)
+	opaque := make([][]bool, dotsH)
+
+	for y := range dotsH {
+		brightness[y] = make([]float64, dotsW)
+		opaque[y] = make([]bool, dotsW)
+
+		for x := range dotsW {
+			pxColor := color.NRGBAModel.Convert(img.At(bounds.Min.X+x, bounds.Min.Y+y)).(color.NRGBA)
+			if convert.ShadeTypeAt(pxColor, baseReadOptions) == convert.ColorTransparent {
+				continue
+			}
+
+			gray := color.GrayModel.Convert(color.NRGBA{pxColor.R, pxColor.G, pxColor.B, 0xff}).(color.Gray)
+			brightness[y][x] = float64(gray.Y) / 0xff
+			opaque[y][x] = true
+		}
+	}
+
+	spreadError := func(x int, y int, amount float64) {
+		if x < 0 || x >= dotsW || y >= dotsH || !opaque[y][x] {
+			return
+		}
+
+		brightness[y][x] += amount
+	}
+
+	shaded := make([][]bool, dotsH)
+	for y := range dotsH {
+		shaded[y] = make([]bool, dotsW)
+
+		for x := range dotsW {
+			if !opaque[y][x] {
+				continue
+			}
+
+			quantized := 1.0
+			if brightness[y][x] < 0.5 {
+				quantized = 0
+				shaded[y][x] = true
+			}
+
+			quantError := brightness[y][x] - quantized
+			spreadError(x+1, y, quantError*7/16)
+			spreadError(x-1, y+1, quantError*3/16)
+			spreadError(x, y+1, quantError*5/16)
+			spreadError(x+1, y+1, quantError*1/16)
+		}
+	}
+
+	return dotsToPixels(shaded, cellH)
+}
+
+func dotsToPixels(shaded [][]bool, cellH int) [][]rune {
+	if len(shaded) == 0 {
+		return nil
+	}
+
+	dotsW, dotsH := len(shaded[0]), len(shaded)
+
+	pixels := make([][]rune, (dotsH+cellH-1)/cellH)
+	for charY := range pixels {
+		pixels[charY] = make([]rune, (dotsW+convert.BRAILLE_WIDTH-1)/convert.BRAILLE_WIDTH)
+
+		for charX := range pixels[charY] {
+			brailleIdx := 0
+
+			for brailleYOff := range cellH {
+				for brailleXOff := range convert.BRAILLE_WIDTH {
+					x := charX*convert.BRAILLE_WIDTH + brailleXOff
+					y := charY*cellH + brailleYOff
+
+					if y < dotsH && x < dotsW && shaded[y][x] {
+						brailleIdx |= convert.DotBit(brailleXOff, brailleYOff)
+					}
+				}
+			}
+
+			pixels[charY][charX] = convert.BitsToRune(brailleIdx)
+		}
+	}
+
+	return pixels
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"testing"
+
+	"github.com/noAbbreviation/benday/convert"
+)
+
+// Shaded dots per band of columns, as a fraction of the dots in the band.
+func bandDensities(pixels [][]rune, bandWidth int) []float64 {
+	bandCount := len(pixels[0]) * convert.BRAILLE_WIDTH / bandWidth
+	shaded := make([]int, bandCount)
+	total := make([]int, bandCount)
+
+	for _, line := range pixels {
+		for charX, pixel := range line {
+			brailleBits, _ := convert.RuneToBits(pixel)
+
+			for dotY := range convert.BRAILLE_HEIGHT {
+				for dotX := range convert.BRAILLE_WIDTH {
+					band := (charX*convert.BRAILLE_WIDTH + dotX) / bandWidth
+					total[band] += 1
+
+					if brailleBits&convert.DotBit(dotX, dotY) != 0 {
+						shaded[band] += 1
+					}
+				}
+			}
+		}
+	}
+
+	densities := make([]float64, bandCount)
+	for band := range densities {
+		densities[band] = float64(shaded[band]) / float64(total[band])
+	}
+
+	return densities
+}
+
+func TestDitherFollowsAGradient(t *testing.T) {
+	const width, height, bandWidth = 128, 32, 16
+
+	// Black on the left to white on the right.
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := range height {
+		for x := range width {
+			gray := uint8(x * 0xff / (width - 1))
+			img.SetNRGBA(x, y, color.NRGBA{gray, gray, gray, 0xff})
+		}
+	}
+
+	// The darkness of a band is how much of it should be shaded.
+	meanError := func(pixels [][]rune) float64 {
+		sum := 0.0
+		for band, density := range bandDensities(pixels, bandWidth) {
+			darkness := 1 - (float64(band)+0.5)*bandWidth/width
+			sum += math.Abs(density - darkness)
+		}
+
+		return sum / (width / bandWidth)
+	}
+
+	thresholded := meanError(rasterToPixels(img, convert.BRAILLE_HEIGHT))
+	dithered := meanError(ditherToPixels(img, convert.BRAILLE_HEIGHT))
+
+	if dithered > 0.05 || dithered*3 > thresholded {
+		t.Errorf("the dots are off the gradient by %.3f dithered and %.3f thresholded, want dithering well under", dithered, thresholded)
+	}
+}
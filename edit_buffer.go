@@ -0,0 +1,107 @@
+package main
+
+import (
+	"errors"
+	"image"
+
+	"github.com/noAbbreviation/benday/convert"
+)
+
+var UnsavedEditsError = errors.New("There are unsaved edits, save them with ctrl+s first.")
+
+// Set from the --buffer-edits flag.
+var bufferEdits = false
+
+// Dot edits, fills, flips, and inverts of a canvas held in memory until saved with ctrl+s,
+// instead of rewriting the file on every key.
+type editBuffer struct {
+	fileName string
+	img      *image.NRGBA
+	measure  convert.CanvasMeasure
+	paddingX int
+	paddingY int
+	edits    int
+}
+
+// Like toggleDot, there is no guard against recently modified files.
+func readEditBuffer(fileName string, paddingX int, paddingY int) (*editBuffer, error) {
+	m, err := getCanvasMeasurement(fileName, paddingX, paddingY)
+	if err != nil {
+		return nil, err
+	}
+
+	img, err := decodeCanvasImage(fileName, m)
+	if err != nil {
+		return nil, err
+	}
+
+	return &editBuffer{fileName, img, m, paddingX, paddingY, 0}, nil
+}
+
+func (b *editBuffer) clone() *editBuffer {
+	cloned := *b
+	cloned.img = copyCanvasImage(b.measure, b.img)
+
+	return &cloned
+}
+
+func (b *editBuffer) pixels(opts convert.ReadOptions) updatePreviewMsg {
+	opts.Ink = b.measure.Ink
+
+	pixels := convert.SamplePixels(b.img, b.measure, opts)
+	colors := convert.SampleColors(b.img, b.measure, opts)
+
+	return updatePreviewMsg{pixels: pixels, colors: colors, img: b.img}
+}
+
+// Loads the buffer on the first edit, and pushes the buffer as it was before the edit
+// to the undo history. Edits that change nothing return false.
+func (m *previewArtModel) editBuffered(edit func(b *editBuffer) bool) error {
+	buffer := m.buffer
+	if buffer == nil {
+		var err error
+		buffer, err = readEditBuffer(m.fileName, m.paddingX, m.paddingY)
+		if err != nil {
+			return err
+		}
+	}
+
+	snapshot := canvasSnapshot{fileName: m.fileName, buffer: buffer.clone()}
+	if !edit(buffer) {
+		return nil
+	}
+
+	buffer.edits += 1
+	m.buffer = buffer
+
+	m.pushUndo(snapshot)
+	m.loadPixels()
+
+	return nil
+}
+
+// Writes the buffered edits to the file, which the watcher picks up again from there.
+func (m *previewArtModel) saveBuffer() error {
+	b := m.buffer
+
+	m.writeSignal <- struct{}{}
+	err := writeCanvasImage(b.fileName, b.img, b.paddingX, b.paddingY, b.measure.CellH, b.measure.Background, b.measure.Align, b.measure.Ink)
+	<-m.writeSignal
+
+	if err != nil {
+		return err
+	}
+
+	// Buffers from before the save are now as many edits away from the file as they were
+	// behind the saved one, so undoing to them leaves edits to save again.
+	for _, snapshot := range m.undoHistory {
+		if before := snapshot.buffer; before != nil && before.fileName == b.fileName {
+			before.edits = b.edits - before.edits
+		}
+	}
+
+	m.buffer = nil
+	m.loadPixels()
+
+	return nil
+}
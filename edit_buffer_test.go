@@ -0,0 +1,97 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"slices"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func pressKeys(m *previewArtModel, keys ...string) {
+	for _, key := range keys {
+		msg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)}
+		switch key {
+		case "esc":
+			msg = tea.KeyMsg{Type: tea.KeyEsc}
+		case " ":
+			msg = tea.KeyMsg{Type: tea.KeySpace, Runes: []rune(key)}
+		}
+
+		m.Update(msg)
+	}
+}
+
+func TestBufferedEditsUndoToTheFile(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	bufferEdits = true
+	t.Cleanup(func() { bufferEdits = false })
+
+	pixels := testPixels(3, 2)
+	fileName := writeTestCanvas(t, pixels, 0, 2)
+
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := newPreviewArtModel(fileName)
+	pressKeys(m, " ", "1", "2", "esc")
+
+	if m.buffer == nil || m.buffer.edits != 2 {
+		t.Fatalf("two dot edits buffered as %+v, want two edits", m.buffer)
+	}
+
+	if slices.EqualFunc(m.pixels, pixels, slices.Equal) {
+		t.Error("the preview does not show the buffered edits")
+	}
+
+	if edited, _ := os.ReadFile(fileName); !bytes.Equal(edited, data) {
+		t.Error("buffered edits were written to the file before saving")
+	}
+
+	pressKeys(m, "u", "u")
+
+	if m.buffer != nil {
+		t.Errorf("undoing every edit left %v unsaved edits", m.buffer.edits)
+	}
+
+	if !slices.EqualFunc(m.pixels, pixels, slices.Equal) {
+		t.Errorf("undoing every edit shows %q, want %q", m.pixels, pixels)
+	}
+}
+
+func TestBufferedEditsSave(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	bufferEdits = true
+	t.Cleanup(func() { bufferEdits = false })
+
+	pixels := testPixels(3, 2)
+	fileName := writeTestCanvas(t, pixels, 0, 2)
+
+	m := newPreviewArtModel(fileName)
+	pressKeys(m, " ", "1", "esc")
+
+	edited := m.pixels
+	if err := m.saveBuffer(); err != nil {
+		t.Fatal(err)
+	}
+
+	if saved := readCanvasPixels(fileName, baseReadOptions); !slices.EqualFunc(saved, edited, slices.Equal) {
+		t.Errorf("saved %q, want %q", saved, edited)
+	}
+
+	// The file has the edit now, so undoing it is an edit to save again.
+	pressKeys(m, "u")
+
+	if m.buffer == nil || m.buffer.edits != 1 {
+		t.Errorf("undoing a saved edit buffered %+v, want one edit", m.buffer)
+	}
+
+	if !slices.EqualFunc(m.pixels, pixels, slices.Equal) {
+		t.Errorf("undoing a saved edit shows %q, want %q", m.pixels, pixels)
+	}
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const ansiReset = "\x1b[0m"
+
+func isANSIFileName(fileName string) bool {
+	return strings.EqualFold(filepath.Ext(fileName), ".ansi")
+}
+
+// Writes braille text with 24-bit color escape codes, for cat-ing in a truecolor terminal.
+// Every line ends with a reset, so colors never bleed into the next line.
+func exportANSI(fileName string, pixels [][]rune, colors [][]color.Color, header string, trim bool, overwrite bool) error {
+	if len(pixels) == 0 || len(pixels[0]) == 0 {
+		return fmt.Errorf("Nothing to export: empty canvas.")
+	}
+
+	if trim {
+		pixels = trimTrailingBlanks(pixels)
+	}
+
+	_, err := os.Stat(fileName)
+	if err == nil && !overwrite {
+		return ExportFileExistsError
+	}
+
+	builder := strings.Builder{}
+	if header != "" {
+		builder.WriteString(header + "\n")
+	}
+
+	for i, line := range pixels {
+		var lineColors []color.Color
+		if i < len(colors) {
+			lineColors = colors[i]
+		}
+
+		builder.WriteString(lineToANSI(line, lineColors))
+		builder.WriteString(ansiReset + "\n")
+	}
+
+	err = os.WriteFile(fileName, []byte(builder.String()), 0644)
+	if err != nil {
+		return fmt.Errorf("Error writing to the file: %v", err)
+	}
+
+	return nil
+}
+
+func lineToANSI(line []rune, colors []color.Color) string {
+	builder := strings.Builder{}
+	currentColor := ""
+
+	for j, pixel := range line {
+		escapeCode := ""
+		if j < len(colors) && colors[j] != nil {
+			r, g, b, _ := colors[j].RGBA()
+			escapeCode = fmt.Sprintf("\x1b[38;2;%v;%v;%vm", r>>8, g>>8, b>>8)
+		}
+
+		if escapeCode != currentColor {
+			if escapeCode == "" {
+				builder.WriteString(ansiReset)
+			} else {
+				builder.WriteString(escapeCode)
+			}
+
+			currentColor = escapeCode
+		}
+
+		builder.WriteRune(pixel)
+	}
+
+	return builder.String()
+}
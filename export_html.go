@@ -0,0 +1,100 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const htmlExportClass = "benday"
+
+func isHTMLFileName(fileName string) bool {
+	return strings.EqualFold(filepath.Ext(fileName), ".html")
+}
+
+// Writes a <pre class="benday"> fragment meant to be pasted into a web page with a braille font.
+// Braille needs no escaping. Colors may be nil, otherwise runs of the same color become spans.
+func exportHTML(fileName string, pixels [][]rune, colors [][]color.Color, header string, trim bool, wrapLines bool, overwrite bool) error {
+	if len(pixels) == 0 || len(pixels[0]) == 0 {
+		return fmt.Errorf("Nothing to export: empty canvas.")
+	}
+
+	if trim {
+		pixels = trimTrailingBlanks(pixels)
+	}
+
+	_, err := os.Stat(fileName)
+	if err == nil && !overwrite {
+		return ExportFileExistsError
+	}
+
+	builder := strings.Builder{}
+	if header != "" {
+		builder.WriteString(fmt.Sprintf("<!-- %v -->\n", header))
+	}
+
+	builder.WriteString(fmt.Sprintf("<pre class=\"%v\">", htmlExportClass))
+	for i, line := range pixels {
+		if i != 0 {
+			builder.WriteRune('\n')
+		}
+
+		if wrapLines {
+			builder.WriteString(fmt.Sprintf("<span class=\"%v-line\">", htmlExportClass))
+		}
+
+		var lineColors []color.Color
+		if i < len(colors) {
+			lineColors = colors[i]
+		}
+
+		builder.WriteString(lineToHTML(line, lineColors))
+
+		if wrapLines {
+			builder.WriteString("</span>")
+		}
+	}
+	builder.WriteString("</pre>\n")
+
+	err = os.WriteFile(fileName, []byte(builder.String()), 0644)
+	if err != nil {
+		return fmt.Errorf("Error writing to the file: %v", err)
+	}
+
+	return nil
+}
+
+func lineToHTML(line []rune, colors []color.Color) string {
+	builder := strings.Builder{}
+	openColor := ""
+
+	for j, pixel := range line {
+		hexColor := ""
+		if j < len(colors) && colors[j] != nil {
+			r, g, b, _ := colors[j].RGBA()
+			hexColor = fmt.Sprintf("#%02x%02x%02x", r>>8, g>>8, b>>8)
+		}
+
+		if hexColor != openColor {
+			if openColor != "" {
+				builder.WriteString("</span>")
+			}
+
+			if hexColor != "" {
+				builder.WriteString(fmt.Sprintf("<span style=\"color: %v\">", hexColor))
+			}
+
+			openColor = hexColor
+		}
+
+		builder.WriteRune(pixel)
+	}
+
+	if openColor != "" {
+		builder.WriteString("</span>")
+	}
+
+	return builder.String()
+}
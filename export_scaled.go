@@ -0,0 +1,146 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"os"
+
+	"github.com/noAbbreviation/benday/convert"
+)
+
+var (
+	ExportFileExistsError = errors.New("File already exists.")
+)
+
+const (
+	defaultExportScale = 8
+	maxExportScale     = 64
+)
+
+type exportFormat int
+
+const (
+	exportBrailleText exportFormat = iota
+	exportSquareDots
+	exportRoundDots
+)
+
+func (format exportFormat) String() string {
+	switch format {
+	case exportSquareDots:
+		return "scaled png, square dots"
+	case exportRoundDots:
+		return "scaled png, round dots"
+	default:
+		return "braille text"
+	}
+}
+
+// Width to height of a braille character in the exported image. Fonts draw characters
+// about twice as tall as wide, while the canvas pixels make padded characters taller.
+type exportAspect struct{ w, h int }
+
+// The zero aspect keeps the proportions of the canvas pixels.
+var exportAspects = []exportAspect{{1, 2}, {1, 1}, {}}
+
+func (aspect exportAspect) String() string {
+	if aspect == (exportAspect{}) {
+		return "as in the canvas"
+	}
+
+	return fmt.Sprintf("%v:%v characters", aspect.w, aspect.h)
+}
+
+// Distance between pixel rows in the exported image, as a fraction of the scale,
+// so a character of brailleW x brailleH canvas pixels comes out at the given aspect.
+func (aspect exportAspect) rowSpacing(m convert.CanvasMeasure, scale int) (int, int) {
+	if aspect == (exportAspect{}) {
+		return scale, 1
+	}
+
+	return scale * m.BrailleW * aspect.h, m.BrailleH * aspect.w
+}
+
+// Every pixel of the canvas becomes a scale x scale block on a white background, and shaded
+// dots are drawn as squares or circles. Only the characters inside bounds are exported.
+// The aspect spreads the rows out or squeezes them together, shrinking the dots if they
+// would overlap.
+func exportScaledPNG(exportFileName string, canvas decodedCanvas, scale int, round bool, aspect exportAspect, bounds image.Rectangle, overwrite bool) error {
+	if _, err := os.Stat(exportFileName); err == nil && !overwrite {
+		return ExportFileExistsError
+	}
+
+	m := canvas.measure
+	bounds = bounds.Intersect(image.Rect(0, 0, m.CharsX, m.CharsY))
+	if bounds.Empty() {
+		return fmt.Errorf("Nothing to export: empty canvas.")
+	}
+
+	spacingNum, spacingDen := aspect.rowSpacing(m, scale)
+	rowY := func(y int) int {
+		return y * spacingNum / spacingDen
+	}
+
+	dotSize := max(min(scale, spacingNum/spacingDen), 1)
+
+	// The trailing padding of the last character is left out, so the dots are centered.
+	trimW, trimH := m.BrailleW-convert.BRAILLE_WIDTH, m.BrailleH-m.CellH
+	rows := bounds.Dy()*m.BrailleH - trimH
+
+	newImage := image.NewNRGBA(image.Rect(
+		0, 0,
+		(bounds.Dx()*m.BrailleW-trimW)*scale,
+		rowY(rows-1)+dotSize,
+	))
+
+	draw.Draw(newImage, newImage.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+
+	opts := baseReadOptions
+	opts.Ink = m.Ink
+
+	origin := canvas.img.Bounds().Min
+	originX, originY := m.DotPixel(bounds.Min.X*convert.BRAILLE_WIDTH, bounds.Min.Y*m.CellH)
+
+	for dotY := bounds.Min.Y * m.CellH; dotY < bounds.Max.Y*m.CellH; dotY += 1 {
+		for dotX := bounds.Min.X * convert.BRAILLE_WIDTH; dotX < bounds.Max.X*convert.BRAILLE_WIDTH; dotX += 1 {
+			x, y := m.DotPixel(dotX, dotY)
+			if convert.ShadeTypeAt(canvas.img.At(origin.X+x, origin.Y+y), opts) != convert.ColorShaded {
+				continue
+			}
+
+			blockX := (x-originX)*scale + (scale-dotSize)/2
+			blockY := rowY(y - originY)
+
+			for yOff := range dotSize {
+				for xOff := range dotSize {
+					if round && !insideDot(xOff, yOff, dotSize) {
+						continue
+					}
+
+					newImage.SetNRGBA(blockX+xOff, blockY+yOff, m.Ink)
+				}
+			}
+		}
+	}
+
+	file, err := os.Create(exportFileName)
+	if err != nil {
+		return fmt.Errorf("Error writing to the file: %v", err)
+	}
+
+	defer file.Close()
+
+	return png.Encode(file, newImage)
+}
+
+// Tests the center of the pixel against a circle filling the block.
+func insideDot(x int, y int, scale int) bool {
+	dx := 2*x + 1 - scale
+	dy := 2*y + 1 - scale
+
+	return dx*dx+dy*dy <= scale*scale
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"path/filepath"
+	"testing"
+)
+
+func TestExportScaledAspect(t *testing.T) {
+	tests := []struct {
+		padding image.Point
+		aspect  exportAspect
+		want    image.Point
+	}{
+		// Characters of 8x8 and 8x16 pixels at a scale of 4, less the rows of the last
+		// dot that would overlap the next character.
+		{image.Pt(0, 0), exportAspect{1, 1}, image.Pt(24, 16)},
+		{image.Pt(0, 0), exportAspect{1, 2}, image.Pt(24, 32)},
+		{image.Pt(0, 0), exportAspect{}, image.Pt(24, 32)},
+
+		// The padding rows of the last character are left out.
+		{image.Pt(0, 2), exportAspect{1, 1}, image.Pt(24, 13)},
+		{image.Pt(0, 2), exportAspect{1, 2}, image.Pt(24, 26)},
+		{image.Pt(0, 2), exportAspect{}, image.Pt(24, 40)},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%vx%v padding, %v", test.padding.X, test.padding.Y, test.aspect), func(t *testing.T) {
+			pixels := [][]rune{[]rune("⣿⣿⣿"), []rune("⣿⣿⣿")}
+			fileName := writeTestCanvas(t, pixels, test.padding.X, test.padding.Y)
+
+			canvas, err := readCanvasFile(fileName, baseReadOptions)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			exportFileName := filepath.Join(t.TempDir(), "export.png")
+			err = exportScaledPNG(exportFileName, canvas, 4, false, test.aspect, image.Rect(0, 0, 3, 2), false)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if size := readTestImage(t, exportFileName).Bounds().Size(); size != test.want {
+				t.Errorf("exported at %v, want %v", size, test.want)
+			}
+		})
+	}
+}
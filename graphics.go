@@ -0,0 +1,48 @@
+package main
+
+import "image"
+
+type graphicsProtocol int
+
+const (
+	graphicsNone graphicsProtocol = iota
+	graphicsSixel
+	graphicsKitty
+)
+
+func (protocol graphicsProtocol) String() string {
+	switch protocol {
+	case graphicsSixel:
+		return "sixel"
+	case graphicsKitty:
+		return "kitty"
+	default:
+		return "none"
+	}
+}
+
+// Kitty graphics are preferred, as they keep the colors of the image as is.
+func detectGraphicsProtocol() graphicsProtocol {
+	if terminalSupportsKitty() {
+		return graphicsKitty
+	}
+
+	if terminalSupportsSixel() {
+		return graphicsSixel
+	}
+
+	return graphicsNone
+}
+
+// Cols and rows are the size of the braille render the image replaces. Sixel images
+// cannot be sized in cells, so they keep their scale instead.
+func encodeTerminalImage(img image.Image, protocol graphicsProtocol, cols int, rows int) string {
+	switch protocol {
+	case graphicsSixel:
+		return encodeSixel(img, sixelScale)
+	case graphicsKitty:
+		return encodeKitty(img, cols, rows)
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,521 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/noAbbreviation/benday/convert"
+)
+
+// With several files each block gets a "--- <file> ---" header, and invalid files
+// are reported without stopping the rest of the batch.
+func runRender(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "Error: --render expects at least one benday file.")
+		return exitUsage
+	}
+
+	exitCode := 0
+	for _, fileName := range args {
+		canvas, err := readCanvasFile(fileName, baseReadOptions)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: Cannot read \"%v\": %v\n", fileName, err)
+			exitCode = exitCodeFor(err)
+
+			continue
+		}
+
+		if len(args) > 1 {
+			fmt.Printf("--- %v ---\n", fileName)
+		}
+
+		fmt.Println(pixelsToText(canvas.pixels))
+	}
+
+	return exitCode
+}
+
+type canvasDescription struct {
+	Width       int      `json:"width"`
+	Height      int      `json:"height"`
+	PaddingX    int      `json:"paddingX"`
+	PaddingY    int      `json:"paddingY"`
+	Unpadded    bool     `json:"unpadded"`
+	Dots        int      `json:"dots"`
+	ImageWidth  int      `json:"imageWidth"`
+	ImageHeight int      `json:"imageHeight"`
+	ShadedDots  int      `json:"shadedDots"`
+	Rows        []string `json:"rows"`
+}
+
+// Width and height are in braille characters, the image size in png pixels.
+func runJSON(args []string) int {
+	if len(args) != 1 {
+		fmt.Fprintln(os.Stderr, "Error: --json expects one benday file.")
+		return exitUsage
+	}
+
+	canvas, err := readCanvasFile(args[0], baseReadOptions)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Cannot read \"%v\": %v\n", args[0], err)
+		return exitCodeFor(err)
+	}
+
+	m := canvas.measure
+	shadedDots, _ := inkDelta(nil, canvas.pixels)
+
+	description := canvasDescription{
+		Width:       m.CharsX,
+		Height:      m.CharsY,
+		PaddingX:    canvas.paddingX,
+		PaddingY:    canvas.paddingY,
+		Unpadded:    m.IsUnpadded,
+		Dots:        m.CellH * convert.BRAILLE_WIDTH,
+		ImageWidth:  m.ImageWidth,
+		ImageHeight: m.ImageHeight,
+		ShadedDots:  shadedDots,
+		Rows:        make([]string, 0, len(canvas.pixels)),
+	}
+
+	for _, line := range canvas.pixels {
+		description.Rows = append(description.Rows, string(line))
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+
+	if err := encoder.Encode(description); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitFailure
+	}
+
+	return 0
+}
+
+func runDiff(args []string) int {
+	if len(args) != 2 {
+		fmt.Fprintln(os.Stderr, "Error: --diff expects two benday files.")
+		return exitUsage
+	}
+
+	before, err := readCanvasFile(args[0], baseReadOptions)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Cannot read \"%v\": %v\n", args[0], err)
+		return exitCodeFor(err)
+	}
+
+	after, err := readCanvasFile(args[1], baseReadOptions)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Cannot read \"%v\": %v\n", args[1], err)
+		return exitCodeFor(err)
+	}
+
+	patch, err := diffCanvases(before.pixels, after.pixels)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitFailure
+	}
+
+	fmt.Print(patch)
+	return 0
+}
+
+func runApply(patchFileName string, args []string) int {
+	if len(args) != 1 {
+		fmt.Fprintln(os.Stderr, "Error: --apply expects one benday file to patch.")
+		return exitUsage
+	}
+
+	patchFile, err := os.Open(patchFileName)
+	if err != nil {
+		err = decodeError{FileDoesNotExistError}
+		fmt.Fprintf(os.Stderr, "Error: Cannot open the patch \"%v\": %v\n", patchFileName, err)
+
+		return exitCodeFor(err)
+	}
+
+	patch, err := parsePatch(patchFile)
+	patchFile.Close()
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitCodeFor(err)
+	}
+
+	if err := applyPatch(args[0], patch); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Cannot apply the patch to \"%v\": %v\n", args[0], err)
+		return exitCodeFor(err)
+	}
+
+	return 0
+}
+
+func runClean(args []string, removeNonGrayscale bool, force bool) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "Error: --clean expects at least one benday file.")
+		return exitUsage
+	}
+
+	exitCode := 0
+	for _, fileName := range args {
+		paddingX, paddingY, err := readCanvasPadding(fileName)
+		if err == nil {
+			if force {
+				err = forceCleanCanvas(fileName, paddingX, paddingY, removeNonGrayscale, baseReadOptions)
+			} else {
+				err = cleanCanvas(fileName, paddingX, paddingY, removeNonGrayscale, baseReadOptions)
+			}
+		}
+
+		if _, isSilent := err.(silentError); isSilent {
+			err = fmt.Errorf("The file was modified less than a second ago, pass --force to clean it anyway.")
+		}
+
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: Cannot clean \"%v\": %v\n", fileName, err)
+			exitCode = exitCodeFor(err)
+
+			continue
+		}
+
+		fmt.Printf("cleaned %v\n", fileName)
+	}
+
+	return exitCode
+}
+
+const lintReportedDots = 5
+
+// Classifies every dot like cleaning would, without writing to the files. Fails if
+// any file has non-grayscale dots, or cannot be read.
+func runLint(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "Error: --lint expects at least one benday file.")
+		return exitUsage
+	}
+
+	exitCode := 0
+	for _, fileName := range args {
+		canvas, err := readCanvasFile(fileName, baseReadOptions)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: Cannot read \"%v\": %v\n", fileName, err)
+			exitCode = exitCodeFor(err)
+
+			continue
+		}
+
+		if counts := printLint(fileName, canvas); counts[convert.ColorNonGrayscale] > 0 {
+			exitCode = exitFailure
+		}
+	}
+
+	return exitCode
+}
+
+// Prints the count of every kind of dot, and where the first non-grayscale ones are.
+func printLint(fileName string, canvas decodedCanvas) map[convert.ShadedType]int {
+	m := canvas.measure
+	origin := canvas.img.Bounds().Min
+
+	opts := baseReadOptions
+	opts.Ink = m.Ink
+
+	counts := map[convert.ShadedType]int{}
+	nonGrayscaleDots := []image.Point{}
+
+	for dotY := range m.CharsY * m.CellH {
+		for dotX := range m.CharsX * convert.BRAILLE_WIDTH {
+			x, y := m.DotPixel(dotX, dotY)
+
+			shade := convert.ShadeTypeAt(canvas.img.At(origin.X+x, origin.Y+y), opts)
+			counts[shade] += 1
+
+			if shade == convert.ColorNonGrayscale && len(nonGrayscaleDots) < lintReportedDots {
+				nonGrayscaleDots = append(nonGrayscaleDots, image.Pt(x, y))
+			}
+		}
+	}
+
+	fmt.Printf(
+		"%v: %v shaded, %v not shaded, %v transparent, %v non-grayscale\n",
+		fileName, counts[convert.ColorShaded], counts[convert.ColorNonShaded], counts[convert.ColorTransparent], counts[convert.ColorNonGrayscale],
+	)
+
+	for _, dot := range nonGrayscaleDots {
+		fmt.Printf("  non-grayscale pixel at %v,%v\n", dot.X, dot.Y)
+	}
+
+	if hidden := counts[convert.ColorNonGrayscale] - len(nonGrayscaleDots); hidden > 0 {
+		fmt.Printf("  and %v more\n", hidden)
+	}
+
+	return counts
+}
+
+// Cleans every benday file under the directory. With dryRun, the files are only linted.
+func runCleanDir(dir string, removeNonGrayscale bool, force bool, dryRun bool, args []string) int {
+	if len(args) != 0 {
+		fmt.Fprintln(os.Stderr, "Error: --clean-dir takes no other arguments.")
+		return exitUsage
+	}
+
+	fileNames := []string{}
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".by.png") {
+			fileNames = append(fileNames, path)
+		}
+
+		return nil
+	})
+
+	if errors.Is(err, fs.ErrNotExist) {
+		err = decodeError{FileDoesNotExistError}
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Cannot walk \"%v\": %v\n", dir, err)
+		return exitCodeFor(err)
+	}
+
+	// Like runRender, the exit code is that of the last file that errored.
+	exitCode := 0
+
+	if dryRun {
+		nonGrayscaleFiles, errored := 0, 0
+		for _, fileName := range fileNames {
+			canvas, err := readCanvasFile(fileName, baseReadOptions)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: Cannot read \"%v\": %v\n", fileName, err)
+				exitCode = exitCodeFor(err)
+				errored += 1
+
+				continue
+			}
+
+			if counts := printLint(fileName, canvas); counts[convert.ColorNonGrayscale] > 0 {
+				nonGrayscaleFiles += 1
+			}
+		}
+
+		fmt.Printf("%v files, %v with non-grayscale dots, %v errored (dry run, nothing written)\n", len(fileNames), nonGrayscaleFiles, errored)
+		return exitCode
+	}
+
+	cleaned, skipped, errored := 0, 0, 0
+	for _, fileName := range fileNames {
+		paddingX, paddingY, err := readCanvasPadding(fileName)
+		if err == nil {
+			if force {
+				err = forceCleanCanvas(fileName, paddingX, paddingY, removeNonGrayscale, baseReadOptions)
+			} else {
+				err = cleanCanvas(fileName, paddingX, paddingY, removeNonGrayscale, baseReadOptions)
+			}
+		}
+
+		if _, isSilent := err.(silentError); isSilent {
+			fmt.Printf("skipped %v, modified less than a second ago\n", fileName)
+			skipped += 1
+
+			continue
+		}
+
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: Cannot clean \"%v\": %v\n", fileName, err)
+			exitCode = exitCodeFor(err)
+			errored += 1
+
+			continue
+		}
+
+		fmt.Printf("cleaned %v\n", fileName)
+		cleaned += 1
+	}
+
+	fmt.Printf("%v cleaned, %v skipped, %v errored\n", cleaned, skipped, errored)
+	return exitCode
+}
+
+// Every pixel of the source image becomes one braille dot.
+func rasterToPixels(img image.Image, cellH int) [][]rune {
+	bounds := img.Bounds()
+	m := convert.CanvasMeasure{
+		ImageWidth:  bounds.Dx(),
+		ImageHeight: bounds.Dy(),
+		CharsX:      (bounds.Dx() + convert.BRAILLE_WIDTH - 1) / convert.BRAILLE_WIDTH,
+		CharsY:      (bounds.Dy() + cellH - 1) / cellH,
+		BrailleW:    convert.BRAILLE_WIDTH,
+		BrailleH:    cellH,
+		CellH:       cellH,
+	}
+
+	return convert.SamplePixels(img, m, baseReadOptions)
+}
+
+func runImportImage(prefix string, paddingSpec string, dither bool, force bool, args []string) int {
+	if len(args) > 1 {
+		fmt.Fprintln(os.Stderr, "Error: --import-image expects at most one image file.")
+		return exitUsage
+	}
+
+	if err := isValidFileName(prefix); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Invalid file name prefix: %v\n", err)
+		return exitUsage
+	}
+
+	paddingX, paddingY, err := parsePaddingSpec(paddingSpec)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitUsage
+	}
+
+	var source io.Reader = os.Stdin
+	if len(args) == 1 {
+		file, err := os.Open(args[0])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: Cannot open \"%v\": %v\n", args[0], FileDoesNotExistError)
+			return exitFileNotFound
+		}
+
+		defer file.Close()
+		source = file
+	} else if !hasStdinPipe() {
+		fmt.Fprintln(os.Stderr, "Error: --import-image expects an image file or piped input.")
+		return exitUsage
+	}
+
+	// The format is detected from the content, so piped input works too. Gifs use their first frame.
+	img, _, err := image.Decode(source)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Cannot read the image: %v\n", err)
+		return exitDecodeError
+	}
+
+	var pixels [][]rune
+	if dither {
+		pixels = ditherToPixels(img, newCanvasCellH)
+	} else {
+		pixels = rasterToPixels(img, newCanvasCellH)
+	}
+
+	if len(pixels) == 0 || len(pixels[0]) == 0 {
+		fmt.Fprintln(os.Stderr, "Error: The image is empty.")
+		return exitFailure
+	}
+
+	fileName := fmt.Sprintf("%v.%vx%v.by.png", prefix, paddingX, paddingY)
+	if _, err := os.Stat(fileName); err == nil && !force {
+		fmt.Fprintf(os.Stderr, "Error: \"%v\" already exists, pass --force to overwrite it.\n", fileName)
+		return exitFailure
+	}
+
+	if err := backupCanvas(fileName); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Cannot back up \"%v\": %v\n", fileName, err)
+		return exitFailure
+	}
+
+	file, err := os.Create(fileName)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Cannot create \"%v\": %v\n", fileName, err)
+		return exitFailure
+	}
+
+	defer file.Close()
+
+	canvasImg := convert.NewPixelsImage(pixels, paddingX, paddingY, newCanvasCellH, convert.AlignStart, baseReadOptions.Ink)
+	if err := encodeCanvas(file, canvasImg, paddingX, paddingY, newCanvasCellH, convert.BackgroundCheckerboard, convert.AlignStart, baseReadOptions.Ink); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Cannot write \"%v\": %v\n", fileName, err)
+		return exitFailure
+	}
+
+	fmt.Println(fileName)
+	return 0
+}
+
+// Fills in the import form from the flags instead of asking, so the file is written like
+// importing piped braille in the interface would.
+func runImportOut(prefix string, paddingSpec string, force bool, args []string) int {
+	if len(args) > 1 {
+		fmt.Fprintln(os.Stderr, "Error: --import-out expects at most one braille text file.")
+		return exitUsage
+	}
+
+	if err := isValidFileName(prefix); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Invalid file name prefix: %v\n", err)
+		return exitUsage
+	}
+
+	paddingX, paddingY, err := parsePaddingSpec(paddingSpec)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitUsage
+	}
+
+	var source io.Reader = os.Stdin
+	if len(args) == 1 {
+		file, err := os.Open(args[0])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: Cannot open \"%v\": %v\n", args[0], FileDoesNotExistError)
+			return exitFileNotFound
+		}
+
+		defer file.Close()
+		source = file
+	} else if !hasStdinPipe() {
+		fmt.Fprintln(os.Stderr, "Error: --import-out expects piped braille ascii or a braille text file.")
+		return exitUsage
+	}
+
+	pixels, stripped, err := importPixelData(source, force)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Cannot import the braille ascii: %v\n", err)
+		return exitCodeFor(decodeError{err})
+	}
+
+	if warning := strippedRunesWarning(stripped); warning != "" {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", warning)
+	}
+
+	importModel := newImportCanvasModel(pixels)
+	importModel.inputs[paddingXInputI].SetValue(strconv.Itoa(paddingX))
+	importModel.inputs[paddingYInputI].SetValue(strconv.Itoa(paddingY))
+	importModel.inputs[fileNameInputI].SetValue(prefix)
+
+	fileName := importModel.fileName()
+
+	// Without --force, createFile refuses to touch an existing file.
+	if force {
+		if err := backupCanvas(fileName); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: Cannot back up \"%v\": %v\n", fileName, err)
+			return exitFailure
+		}
+	}
+
+	err = importModel.createFile(force)
+	if errors.Is(err, ExportFileExistsError) {
+		fmt.Fprintf(os.Stderr, "Error: \"%v\" already exists, pass --force to overwrite it.\n", fileName)
+		return exitFailure
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Cannot write \"%v\": %v\n", fileName, err)
+		return exitFailure
+	}
+
+	fmt.Println(fileName)
+	return 0
+}
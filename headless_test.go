@@ -0,0 +1,84 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// A file named like a canvas, with content that is not a png.
+func writeGarbageCanvas(t *testing.T, dir string) string {
+	t.Helper()
+
+	fileName := filepath.Join(dir, "garbage.0x2.by.png")
+	if err := os.WriteFile(fileName, []byte("not a png"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ageTestFile(t, fileName)
+	return fileName
+}
+
+func TestRunCleanExitCodes(t *testing.T) {
+	missing := filepath.Join(t.TempDir(), "missing.0x2.by.png")
+	if code := runClean([]string{missing}, false, false); code != exitFileNotFound {
+		t.Errorf("cleaning a missing file exits with %v, want %v", code, exitFileNotFound)
+	}
+
+	garbage := writeGarbageCanvas(t, t.TempDir())
+	if code := runClean([]string{garbage}, false, true); code != exitDecodeError {
+		t.Errorf("cleaning a file that is not a png exits with %v, want %v", code, exitDecodeError)
+	}
+
+	canvas := writeTestCanvas(t, testPixels(3, 2), 0, 2)
+	if code := runClean([]string{canvas}, false, false); code != 0 {
+		t.Errorf("cleaning a canvas exits with %v, want 0", code)
+	}
+}
+
+func TestRunApplyExitCodes(t *testing.T) {
+	dir := t.TempDir()
+	canvas := writeTestCanvas(t, testPixels(3, 2), 0, 2)
+
+	missingPatch := filepath.Join(dir, "missing.patch")
+	if code := runApply(missingPatch, []string{canvas}); code != exitFileNotFound {
+		t.Errorf("applying a missing patch exits with %v, want %v", code, exitFileNotFound)
+	}
+
+	patchFileName := filepath.Join(dir, "empty.patch")
+	if err := os.WriteFile(patchFileName, []byte(patchHeaderPrefix+" 3x2\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	missing := filepath.Join(dir, "missing.0x2.by.png")
+	if code := runApply(patchFileName, []string{missing}); code != exitFileNotFound {
+		t.Errorf("patching a missing file exits with %v, want %v", code, exitFileNotFound)
+	}
+
+	garbage := writeGarbageCanvas(t, dir)
+	if code := runApply(patchFileName, []string{garbage}); code != exitDecodeError {
+		t.Errorf("patching a file that is not a png exits with %v, want %v", code, exitDecodeError)
+	}
+
+	if code := runApply(patchFileName, []string{canvas}); code != 0 {
+		t.Errorf("patching a canvas exits with %v, want 0", code)
+	}
+}
+
+func TestRunCleanDirExitCodes(t *testing.T) {
+	missing := filepath.Join(t.TempDir(), "missing")
+	for _, dryRun := range []bool{false, true} {
+		if code := runCleanDir(missing, false, false, dryRun, nil); code != exitFileNotFound {
+			t.Errorf("cleaning a missing directory (dry run: %v) exits with %v, want %v", dryRun, code, exitFileNotFound)
+		}
+	}
+
+	dir := t.TempDir()
+	writeGarbageCanvas(t, dir)
+
+	for _, dryRun := range []bool{false, true} {
+		if code := runCleanDir(dir, false, true, dryRun, nil); code != exitDecodeError {
+			t.Errorf("cleaning a file that is not a png (dry run: %v) exits with %v, want %v", dryRun, code, exitDecodeError)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/png"
+	"os"
+	"strings"
+)
+
+// Escape sequences carry at most this much base64 data each.
+const kittyChunkSize = 4096
+
+// Like sixel, detected through the environment only.
+func terminalSupportsKitty() bool {
+	if os.Getenv("KITTY_WINDOW_ID") != "" {
+		return true
+	}
+
+	switch os.Getenv("TERM") {
+	case "xterm-kitty", "xterm-ghostty":
+		return true
+	}
+
+	switch os.Getenv("TERM_PROGRAM") {
+	case "ghostty", "WezTerm":
+		return true
+	}
+
+	return false
+}
+
+// Deletes every image placed on the screen, so a new one does not stack on the old.
+const kittyDeleteImages = "\x1b_Ga=d,q=2\x1b\\"
+
+// The terminal stretches the image over cols by rows cells without moving the cursor,
+// and the returned view reserves those cells with blank lines.
+func encodeKitty(img image.Image, cols int, rows int) string {
+	if img.Bounds().Empty() || cols <= 0 || rows <= 0 {
+		return ""
+	}
+
+	buffer := bytes.Buffer{}
+	if err := png.Encode(&buffer, img); err != nil {
+		return ""
+	}
+
+	data := base64.StdEncoding.EncodeToString(buffer.Bytes())
+
+	builder := strings.Builder{}
+	builder.WriteString(kittyDeleteImages)
+
+	for start := 0; start < len(data); start += kittyChunkSize {
+		end := min(start+kittyChunkSize, len(data))
+
+		hasMore := "0"
+		if end < len(data) {
+			hasMore = "1"
+		}
+
+		builder.WriteString("\x1b_G")
+		if start == 0 {
+			builder.WriteString(fmt.Sprintf("a=T,f=100,q=2,C=1,c=%v,r=%v,", cols, rows))
+		}
+
+		builder.WriteString("m=" + hasMore + ";")
+		builder.WriteString(data[start:end])
+		builder.WriteString("\x1b\\")
+	}
+
+	builder.WriteString(strings.Repeat("\n", rows-1))
+	return builder.String()
+}
@@ -1,42 +1,334 @@
 package main
 
 import (
+	"encoding/hex"
+	"errors"
+	"flag"
 	"fmt"
+	"image"
+	"image/color"
 	"os"
+	"strings"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
-)
-
-const (
-	BRAILLE_HEIGHT = 4
-	BRAILLE_WIDTH  = 2
+	"github.com/noAbbreviation/benday/convert"
 )
 
 func main() {
+	for _, warning := range loadConfig() {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", warning)
+	}
+
+	escToMenu := flag.Bool("esc-to-menu", false, "return to the start menu instead of quitting when pressing esc on a file opened from the command line")
+	renderMode := flag.Bool("render", false, "print the braille characters of a benday file to stdout without opening the interface")
+	jsonMode := flag.Bool("json", false, "print a json description of a benday file to stdout, with its size, padding, and rows of braille characters")
+	diffMode := flag.Bool("diff", false, "print a patch of the characters that changed between two benday files")
+	patchFileName := flag.String("apply", "", "apply a patch made with --diff to the benday file given as argument")
+	importImagePrefix := flag.String("import-image", "", "convert a png, jpeg, or gif (given as argument or piped) into a benday file with this name prefix")
+	importOutPrefix := flag.String("import-out", "", "convert piped braille ascii (or a braille text file given as argument) into a benday file with this name prefix, without the interface")
+	dither := flag.Bool("dither", false, "dither the image imported by --import-image instead of thresholding it")
+	paddingSpec := flag.String("padding", fmt.Sprintf("%vx%v", defaultPaddingX, defaultPaddingY), "padding of the benday file created by --import-image or --import-out, or of previewed files whose name lacks it, in the form <pX>x<pY>")
+	sixDot := flag.Bool("six-dot", false, "create six-dot (2x3) braille canvases instead of eight-dot (2x4) ones")
+	clean := flag.Bool("clean", false, "clean the benday files given as arguments in place, like pressing c in the preview")
+	lint := flag.Bool("lint", false, "count the shaded, transparent, and non-grayscale dots of benday files without changing them, failing on non-grayscale dots")
+	cleanDir := flag.String("clean-dir", "", "clean every benday file under this directory in place, like --clean (or --clean-strict)")
+	dryRun := flag.Bool("dry-run", false, "with --clean-dir, report the dots of each file like --lint instead of cleaning them")
+	cleanStrict := flag.Bool("clean-strict", false, "like --clean, but also remove non-grayscale colors, like pressing C in the preview")
+	force := flag.Bool("force", false, "let --clean write to files modified less than a second ago, --import-image and --import-out overwrite an existing file, and braille text that is mostly not braille be imported")
+	buffered := flag.Bool("buffer-edits", false, "keep dot edits, fills, flips, and inverts in memory until saved with ctrl+s, pausing the file watcher meanwhile")
+	backup := flag.Bool("backup", false, "copy benday files to <name>.bak before rewriting them in place, replacing the backup of the previous change")
+	inkSpec := flag.String("ink", defaultInkSpec, "color of the shaded dots written to benday files, in the form RRGGBB, dark enough to read as shaded")
+	invert := flag.Bool("invert", false, "read light dots on a dark background as shaded, for art drawn light on dark (I in the preview)")
+	luma := flag.Bool("luma", false, "decide shading by the luminance of pixels, so colored pixels are shaded by how dark they look instead of being ignored")
+	alpha := flag.Uint("alpha", uint(convert.DefaultMinOpaqueAlpha), "pixels with less alpha than this (1-255) are transparent, lower it to keep faint anti-aliased dots")
+	if envInterval := os.Getenv("BENDAY_WATCH_INTERVAL"); envInterval != "" {
+		interval, err := time.ParseDuration(envInterval)
+		if err != nil || interval <= 0 {
+			fmt.Fprintf(os.Stderr, "Error: BENDAY_WATCH_INTERVAL must be a positive duration like 250ms, but is \"%v\".\n", envInterval)
+			os.Exit(exitUsage)
+		}
+
+		defaultWatchInterval = interval
+	}
+
+	maxPixels := flag.Int("max-pixels", maxCanvasPixels, "refuse to create canvases with more pixels than this")
+	watchInterval := flag.Duration("watch-interval", defaultWatchInterval, "how often the preview checks the file for changes (also set by BENDAY_WATCH_INTERVAL)")
+
+	flag.Usage = printUsage
+	flag.Parse()
+
+	if *watchInterval <= 0 {
+		fmt.Fprintln(os.Stderr, "Error: --watch-interval must be a positive duration like 250ms.")
+		os.Exit(exitUsage)
+	}
+
+	defaultWatchInterval = *watchInterval
+
+	if *maxPixels <= 0 {
+		fmt.Fprintln(os.Stderr, "Error: --max-pixels must be a positive number.")
+		os.Exit(exitUsage)
+	}
+
+	maxCanvasPixels = *maxPixels
+
+	ink, err := parseInkColor(*inkSpec)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitUsage)
+	}
+
+	if *alpha < 1 || *alpha > 0xff {
+		fmt.Fprintf(os.Stderr, "Error: --alpha must be between 1 and 255, but is %v.\n", *alpha)
+		os.Exit(exitUsage)
+	}
+
+	baseReadOptions.MinOpaqueAlpha = uint32(*alpha)
+	baseReadOptions.Invert = *invert
+	baseReadOptions.Luma = *luma
+	baseReadOptions.Ink = ink
+
+	if *sixDot {
+		newCanvasCellH = convert.SIX_DOT_BRAILLE_HEIGHT
+	}
+
+	forceImport = *force
+	keepBackups = *backup
+	bufferEdits = *buffered
+
+	switch {
+	case *renderMode:
+		os.Exit(runRender(flag.Args()))
+	case *jsonMode:
+		os.Exit(runJSON(flag.Args()))
+	case *diffMode:
+		os.Exit(runDiff(flag.Args()))
+	case *patchFileName != "":
+		os.Exit(runApply(*patchFileName, flag.Args()))
+	case *importImagePrefix != "":
+		os.Exit(runImportImage(*importImagePrefix, *paddingSpec, *dither, *force, flag.Args()))
+	case *importOutPrefix != "":
+		os.Exit(runImportOut(*importOutPrefix, *paddingSpec, *force, flag.Args()))
+	case *lint:
+		os.Exit(runLint(flag.Args()))
+	case *cleanDir != "":
+		os.Exit(runCleanDir(*cleanDir, *cleanStrict, *force, *dryRun, flag.Args()))
+	case *clean || *cleanStrict:
+		os.Exit(runClean(flag.Args(), *cleanStrict, *force))
+	}
+
 	var model tea.Model
 
 	switch {
 	case hasStdinPipe():
-		pixels, err := importPixelData(os.Stdin)
+		pixels, stripped, err := importPixelData(os.Stdin, forceImport)
 		if err != nil {
-			fmt.Printf("Error: Cannot import from piped input: %v", err)
-			os.Exit(1)
+			fmt.Fprintf(os.Stderr, "Error: Cannot import from piped input: %v\n", err)
+			os.Exit(exitCodeFor(decodeError{err}))
 		}
 
-		model = importCanvasModelFromArgs(pixels)
+		importModel := importCanvasModelFromArgs(pixels)
+		importModel.warning = strippedRunesWarning(stripped)
 
-	case len(os.Args) >= 2:
-		fileName := os.Args[1]
-		model = previewArtModelFromArgs(fileName)
+		model = importModel
+
+	case flag.NArg() >= 1:
+		// Only an explicit --padding overrides the padding of previewed files, not its default.
+		var paddingOverride *image.Point
+		flag.Visit(func(f *flag.Flag) {
+			if f.Name != "padding" {
+				return
+			}
+
+			paddingX, paddingY, err := parsePaddingSpec(*paddingSpec)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(exitUsage)
+			}
+
+			paddingOverride = &image.Point{paddingX, paddingY}
+		})
+
+		fileNames, err := openableFiles(flag.Args(), paddingOverride)
+		if len(fileNames) == 0 {
+			os.Exit(exitCodeFor(err))
+		}
+
+		previewModel := previewArtModelFromArgs(fileNames, paddingOverride)
+		previewModel._escToMenu = *escToMenu
+
+		model = previewModel
 
 	default:
 		model = newBendayStartModel()
 	}
 
 	p := tea.NewProgram(model)
-	if _, err := p.Run(); err != nil {
-		fmt.Printf("Alas, there's been an error: %v", err)
-		os.Exit(1)
+	finalModel, err := p.Run()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Alas, there's been an error: %v\n", err)
+		os.Exit(exitFailure)
+	}
+
+	// The message itself was the last thing drawn, so only the exit code is left to set.
+	if _, hasPanicked := finalModel.(panicMsgModel); hasPanicked {
+		os.Exit(exitFailure)
+	}
+}
+
+var previewKeybindings = [][2]string{
+	{"t", "toggle padding between padded and unpadded"},
+	{"P", "re-pad the canvas to a typed <pX>x<pY> padding, renaming the file to match"},
+	{"c / C", "clean the canvas (C also removes non-grayscale colors)"},
+	{"r", "resize the canvas, with +/- or a typed character count"},
+	{"R", "reset the canvas to blank"},
+	{"h", "flip the canvas horizontally"},
+	{"J / V", "flip the canvas vertically"},
+	{"{ / }", "rotate the canvas left or right, swapping the padding in the file name"},
+	{"x", "crop the canvas to its content"},
+	{"i", "invert the canvas, shading blank dots and clearing shaded ones"},
+	{"I", "read light dots on a dark background as shaded, without changing the file (like --invert)"},
+	{"arrows", "pan around canvases larger than the terminal"},
+	{"+ / -", "zoom the preview in or out, without changing the file"},
+	{"space", "edit the canvas: arrows move the cursor, 1-8 toggle the dots of a character, f fills the connected blank or shaded area, shift+arrows select a rectangle to export on its own, m mirrors edits across the middle (none, vertical, horizontal, both)"},
+	{"p", "pause or resume watching the file for changes"},
+	{"u", "undo the last change to the canvas, up to 10 changes back"},
+	{"ctrl+s", "save the edits held in memory with --buffer-edits"},
+	{"e", "export the braille characters to a text file, or only the selection if there is one"},
+	{"w", "save a copy of the canvas under a new name, and preview the copy"},
+	{"D", "duplicate the canvas under the next free name to branch off a variation, keeping the original"},
+	{"y", "copy the braille characters to the clipboard"},
+	{"s", "show the source image instead of braille (sixel and kitty terminals only)"},
+	{"v", "toggle between monochrome and colored preview"},
+	{"m", "tint each character by how many of its dots are shaded, to spot dense and empty areas"},
+	{"b", "show blank characters as spaces, for fonts that draw ⠀ with a box (also when exporting and copying)"},
+	{"g", "toggle rulers around the canvas, labeling every 5th column and row"},
+	{"n", "number every row and column around the canvas, with column numbers read downwards"},
+	{"[ / ]", "lower or raise the shading threshold"},
+	{"tab", "switch to the previously opened file"},
+	{"ctrl-n/p", "cycle through the files given as arguments"},
+	{"?", "show these keys"},
+	{"esc", "go back"},
+	{"ctrl-c", "exit the program"},
+}
+
+func printUsage() {
+	output := flag.CommandLine.Output()
+
+	fmt.Fprintln(output, "Usage:")
+	fmt.Fprintln(output, "  benday                          open the start menu")
+	fmt.Fprintln(output, "  benday <file>                   preview a benday file")
+	fmt.Fprintln(output, "  benday --padding <pX>x<pY> <file>")
+	fmt.Fprintln(output, "                                  preview a benday file that lost the padding in its name")
+	fmt.Fprintln(output, "  <command> | benday              import piped braille ascii into a new benday file")
+	fmt.Fprintln(output, "  <command> | benday --import-out <prefix> [--padding <pX>x<pY>] [--force]")
+	fmt.Fprintln(output, "                                  import piped braille ascii without the interface")
+	fmt.Fprintln(output, "  benday --render <file>          print the braille characters of a benday file")
+	fmt.Fprintln(output, "  benday --json <file>            print a json description of a benday file")
+	fmt.Fprintln(output, "  benday --diff <before> <after>  print a patch between two benday files")
+	fmt.Fprintln(output, "  benday --apply <patch> <file>   apply a patch to a benday file")
+	fmt.Fprintln(output, "  benday --import-image <prefix> [--dither] [--force] [image]")
+	fmt.Fprintln(output, "                                  convert a png, jpeg, or gif into a benday file")
+	fmt.Fprintln(output, "  benday --clean [--force] <file>...")
+	fmt.Fprintln(output, "                                  clean benday files in place (--clean-strict for C)")
+	fmt.Fprintln(output, "  benday --lint <file>...         report non-grayscale dots without changing the files")
+	fmt.Fprintln(output, "  benday --clean-dir <dir> [--clean-strict] [--force] [--dry-run]")
+	fmt.Fprintln(output, "                                  clean every benday file under a directory")
+	fmt.Fprintln(output)
+	fmt.Fprintln(output, "Benday files are named \"<name>.<pX>x<pY>.by.png\", where pX and pY are the")
+	fmt.Fprintln(output, "horizontal and vertical padding between braille characters, in dots.")
+	fmt.Fprintln(output, "Six-dot canvases (see --six-dot) are marked in the png metadata, and read as such.")
+	fmt.Fprintln(output)
+	fmt.Fprintln(output, "Defaults can be set in <config dir>/benday/config.json (see the README).")
+	fmt.Fprintln(output)
+	fmt.Fprintln(output, "Exit codes: 1 failure, 2 invalid usage, 3 file not found, 4 invalid image")
+	fmt.Fprintln(output, "dimensions, 5 unreadable or invalid benday file.")
+	fmt.Fprintln(output)
+	fmt.Fprintln(output, formatKeybindings("Preview keybindings:", previewKeybindings))
+
+	fmt.Fprintln(output)
+	fmt.Fprintln(output, "Flags:")
+	flag.PrintDefaults()
+}
+
+// Set from the --alpha, --invert, --luma, and --ink flags. The preview adjusts the threshold
+// and reading light on dark on its own (see previewArtModel.readOptions).
+var baseReadOptions = convert.DefaultReadOptions()
+
+func parseInkColor(spec string) (color.NRGBA, error) {
+	hexColor := strings.TrimPrefix(spec, "#")
+
+	rgb, err := hex.DecodeString(hexColor)
+	if err != nil || len(rgb) != 3 {
+		return color.NRGBA{}, fmt.Errorf("--ink must be a color in the form RRGGBB, but is \"%v\".", spec)
+	}
+
+	ink := color.NRGBA{rgb[0], rgb[1], rgb[2], 0xff}
+
+	// Judged by luminance, so colored inks count by how dark they look. The dots then also
+	// read as shaded by their darkness alone, like those of the default ink.
+	darkness := convert.DefaultReadOptions()
+	darkness.Luma = true
+	darkness.Ink = color.NRGBA{}
+
+	if convert.ShadeTypeAt(ink, darkness) != convert.ColorShaded {
+		return color.NRGBA{}, fmt.Errorf("--ink must be darker than %v brightness, but \"%v\" is too light.", darkness.Threshold, spec)
+	}
+
+	return ink, nil
+}
+
+// Files that cannot be opened are reported and left out, instead of stopping the preview.
+// The error is of the last file left out.
+func openableFiles(fileNames []string, paddingOverride *image.Point) ([]string, error) {
+	openable := []string{}
+	lastErr := error(nil)
+
+	for _, fileName := range fileNames {
+		src := previewSource{fileName: fileName, paddingOverride: paddingOverride, options: baseReadOptions}
+
+		_, err := src.readCanvas()
+		if _, isDecodeError := err.(decodeError); isDecodeError {
+			fmt.Fprintf(os.Stderr, "Error: Cannot open \"%v\": %v\n", fileName, err)
+			lastErr = err
+
+			continue
+		}
+
+		openable = append(openable, fileName)
+	}
+
+	return openable, lastErr
+}
+
+// Usage errors exit with 2, and each class of unreadable file with its own code, so
+// scripts can tell a bad invocation from a bad file.
+const (
+	exitFailure           = 1
+	exitUsage             = 2
+	exitFileNotFound      = 3
+	exitInvalidDimensions = 4
+	exitDecodeError       = 5
+)
+
+func exitCodeFor(err error) int {
+	switch err := err.(type) {
+	case nil:
+		return 0
+	case convert.InvalidImgDimensionE:
+		return exitInvalidDimensions
+	case decodeError:
+		if errors.Is(err.error, FileDoesNotExistError) {
+			return exitFileNotFound
+		}
+
+		if _, isDimensionError := err.error.(convert.InvalidImgDimensionE); isDimensionError {
+			return exitInvalidDimensions
+		}
+
+		return exitDecodeError
+	default:
+		return exitFailure
 	}
 }
 
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"image/color"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/noAbbreviation/benday/convert"
+)
+
+func TestParseInkColorRejectsLightInks(t *testing.T) {
+	for _, spec := range []string{"333333", "#000000", "ff0000", "0000ff"} {
+		if _, err := parseInkColor(spec); err != nil {
+			t.Errorf("%v is refused: %v", spec, err)
+		}
+	}
+
+	for _, spec := range []string{"ffffff", "cccccc", "ffff00", "33333", "gray"} {
+		if _, err := parseInkColor(spec); err == nil {
+			t.Errorf("%v is accepted", spec)
+		}
+	}
+}
+
+// The ink chunk is read over the ink of the read options, so the dots of a file written
+// with another --ink stay shaded, even through a strict clean.
+func TestInkChunkReadsAsShaded(t *testing.T) {
+	red := color.NRGBA{0xff, 0, 0, 0xff}
+	pixels := testPixels(4, 3)
+
+	fileName := filepath.Join(t.TempDir(), "test.1x2.by.png")
+	img := convert.NewPixelsImage(pixels, 1, 2, convert.BRAILLE_HEIGHT, convert.AlignStart, red)
+
+	err := writeCanvasImage(fileName, img, 1, 2, convert.BRAILLE_HEIGHT, convert.BackgroundCheckerboard, convert.AlignStart, red)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ageTestFile(t, fileName)
+	opts := convert.DefaultReadOptions()
+
+	if read := readCanvasPixels(fileName, opts); !slices.EqualFunc(read, pixels, slices.Equal) {
+		t.Errorf("read %q, want %q", read, pixels)
+	}
+
+	if err := cleanCanvas(fileName, 1, 2, true, opts); err != nil {
+		t.Fatal(err)
+	}
+
+	if read := readCanvasPixels(fileName, opts); !slices.EqualFunc(read, pixels, slices.Equal) {
+		t.Errorf("read %q after cleaning, want %q", read, pixels)
+	}
+
+	m, err := getCanvasMeasurement(fileName, 1, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The first character of testPixels has its top left dot.
+	dotX, dotY := m.DotPixel(0, 0)
+	if dot := readTestImage(t, fileName).NRGBAAt(dotX, dotY); dot != red {
+		t.Errorf("the dot is written as %v after cleaning, want red", dot)
+	}
+}
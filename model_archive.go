@@ -0,0 +1,242 @@
+package main
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+var archiveExtensions = []string{".zip", ".tar", ".tar.gz", ".tgz"}
+
+var (
+	NoArchiveCanvasesError = errors.New("Archive does not contain any benday files.")
+	ReadOnlyArchiveError   = errors.New("Archive members are read-only.")
+)
+
+type archiveMemberReader struct {
+	io.Reader
+	closers []io.Closer
+}
+
+func (r archiveMemberReader) Close() error {
+	var closeErr error
+	for _, closer := range slices.Backward(r.closers) {
+		if err := closer.Close(); err != nil && closeErr == nil {
+			closeErr = err
+		}
+	}
+
+	return closeErr
+}
+
+func isZipArchive(archivePath string) bool {
+	return strings.HasSuffix(archivePath, ".zip")
+}
+
+func openTarArchive(archivePath string) (*tar.Reader, []io.Closer, error) {
+	file, err := os.Open(archivePath)
+	if err != nil {
+		return nil, nil, FileDoesNotExistError
+	}
+
+	closers := []io.Closer{file}
+	if strings.HasSuffix(archivePath, ".gz") || strings.HasSuffix(archivePath, ".tgz") {
+		gzipReader, err := gzip.NewReader(file)
+		if err != nil {
+			file.Close()
+			return nil, nil, fmt.Errorf("Error reading the archive: %w", err)
+		}
+
+		closers = append(closers, gzipReader)
+		return tar.NewReader(gzipReader), closers, nil
+	}
+
+	return tar.NewReader(file), closers, nil
+}
+
+func listArchiveCanvases(archivePath string) ([]string, error) {
+	members := []string{}
+
+	if isZipArchive(archivePath) {
+		zipReader, err := zip.OpenReader(archivePath)
+		if err != nil {
+			return nil, fmt.Errorf("Error reading the archive: %w", err)
+		}
+
+		defer zipReader.Close()
+
+		for _, file := range zipReader.File {
+			if !file.FileInfo().IsDir() && strings.HasSuffix(file.Name, ".by.png") {
+				members = append(members, file.Name)
+			}
+		}
+	} else {
+		tarReader, closers, err := openTarArchive(archivePath)
+		if err != nil {
+			return nil, err
+		}
+
+		defer archiveMemberReader{nil, closers}.Close()
+
+		for {
+			header, err := tarReader.Next()
+			if err == io.EOF {
+				break
+			}
+
+			if err != nil {
+				return nil, fmt.Errorf("Error reading the archive: %w", err)
+			}
+
+			if header.Typeflag == tar.TypeReg && strings.HasSuffix(header.Name, ".by.png") {
+				members = append(members, header.Name)
+			}
+		}
+	}
+
+	if len(members) == 0 {
+		return nil, NoArchiveCanvasesError
+	}
+
+	slices.Sort(members)
+	return members, nil
+}
+
+func openArchiveMember(archivePath string, memberName string) (io.ReadCloser, error) {
+	if isZipArchive(archivePath) {
+		zipReader, err := zip.OpenReader(archivePath)
+		if err != nil {
+			return nil, fmt.Errorf("Error reading the archive: %w", err)
+		}
+
+		file, err := zipReader.Open(memberName)
+		if err != nil {
+			zipReader.Close()
+			return nil, FileDoesNotExistError
+		}
+
+		return archiveMemberReader{file, []io.Closer{zipReader, file}}, nil
+	}
+
+	tarReader, closers, err := openTarArchive(archivePath)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		header, err := tarReader.Next()
+		if err != nil {
+			archiveMemberReader{nil, closers}.Close()
+			return nil, FileDoesNotExistError
+		}
+
+		if header.Name == memberName {
+			return archiveMemberReader{tarReader, closers}, nil
+		}
+	}
+}
+
+type archiveModel struct {
+	archivePath string
+	members     []string
+
+	focused int
+	err     error
+}
+
+func newArchiveModel(archivePath string) *archiveModel {
+	members, err := listArchiveCanvases(archivePath)
+
+	return &archiveModel{
+		archivePath: archivePath,
+		members:     members,
+		err:         err,
+	}
+}
+
+func (m *archiveModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m *archiveModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	keyMsg, isKeyMsg := msg.(tea.KeyMsg)
+	if !isKeyMsg {
+		return m, nil
+	}
+
+	switch keyMsg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "esc":
+		startModel := newBendayStartModel()
+		return startModel, startModel.Init()
+	}
+
+	if m.err != nil {
+		startModel := newBendayStartModel()
+		return startModel, startModel.Init()
+	}
+
+	switch keyMsg.String() {
+	case "?":
+		return newHelpOverlay(m), nil
+	case "tab", "down", "ctrl+n", "j":
+		m.focused = (m.focused + 1) % len(m.members)
+
+	case "shift+tab", "up", "ctrl+p", "k":
+		m.focused -= 1
+
+		if m.focused < 0 {
+			m.focused = len(m.members) - 1
+		}
+
+	case "enter":
+		previewModel := newArchivePreviewArtModel(m.archivePath, m.members[m.focused])
+		return previewModel, previewModel.Init()
+	}
+
+	return m, nil
+}
+
+func (m *archiveModel) View() string {
+	if m.err != nil {
+		return lipgloss.JoinVertical(
+			lipgloss.Left,
+			"",
+			fmt.Sprintf("Error opening the archive \"%v\":", m.archivePath),
+			m.err.Error(),
+			"",
+			"(opening archive failed) (any key to go back)",
+		)
+	}
+
+	members := make([]string, len(m.members))
+	for i, member := range m.members {
+		selectedStr := " "
+		if m.focused == i {
+			selectedStr = "+"
+		}
+
+		members[i] = fmt.Sprintf("  [%v] %v", selectedStr, member)
+	}
+
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		"",
+		fmt.Sprintf("  Archive: %v", m.archivePath),
+		"",
+		strings.Join(members, "\n"),
+		"",
+		"(archive) (up/down to select, enter to preview read-only, ? for help, esc to go back, ctrl-c to exit program)",
+		"",
+	)
+}
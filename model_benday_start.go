@@ -2,22 +2,40 @@ package main
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
+	"image"
+	"io"
+	"net/url"
 	"os"
+	"path/filepath"
 	"strings"
 
+	"github.com/atotto/clipboard"
 	"github.com/charmbracelet/bubbles/filepicker"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/noAbbreviation/benday/convert"
 )
 
 type bendayStartModel struct {
-	focusedOpt    int
-	selectingFile bool
-	importingFile bool
-
-	filePicker filepicker.Model
+	focusedOpt       int
+	selectingFile    bool
+	importingFile    bool
+	importingImage   bool
+	selectingProject bool
+	selectingArchive bool
+
+	filePicker filteredFilePicker
 	err        error
+
+	deletingFile string
+	deleteErr    error
+
+	droppedFile string
+	dropErr     error
+
+	pasteErr error
 }
 
 func newBendayStartModel() *bendayStartModel {
@@ -27,15 +45,18 @@ func newBendayStartModel() *bendayStartModel {
 	return &newModel
 }
 
-func (_ *bendayStartModel) newFilePicker() filepicker.Model {
+func (_ *bendayStartModel) newFilePicker() filteredFilePicker {
 	filePicker := filepicker.New()
 	filePicker.AllowedTypes = []string{".by.png"}
 	filePicker.AutoHeight = false
 	filePicker.SetHeight(10)
 	filePicker.ShowPermissions = false
 	filePicker.CurrentDirectory, _ = os.Getwd()
+	if lastDirectory != "" {
+		filePicker.CurrentDirectory = lastDirectory
+	}
 
-	return filePicker
+	return filteredFilePicker{Model: filePicker}
 }
 
 func (m *bendayStartModel) Init() tea.Cmd {
@@ -49,19 +70,41 @@ func (m *bendayStartModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case "ctrl+c":
 			return m, tea.Quit
 		case "esc":
-			if m.selectingFile || m.importingFile {
+			if m.deletingFile != "" || m.deleteErr != nil {
+				m.deletingFile = ""
+				m.deleteErr = nil
+
+				return m, nil
+			}
+
+			if m.isPickingFile() && m.filePicker.filter != "" {
+				m.filePicker.ClearFilter()
+				return m, nil
+			}
+
+			if m.isPickingFile() {
 				m.selectingFile = false
 				m.importingFile = false
+				m.importingImage = false
+				m.selectingProject = false
+				m.selectingArchive = false
 
 				m.filePicker = m.newFilePicker()
 				return m, m.filePicker.Init()
 			}
 
+			if m.droppedFile != "" || m.dropErr != nil {
+				m.droppedFile = ""
+				m.dropErr = nil
+
+				return m, nil
+			}
+
 			return m, tea.Quit
 		}
 	}
 
-	if m.selectingFile || m.importingFile {
+	if m.isPickingFile() {
 		if m.err != nil {
 			if _, isKeyMsg := msg.(tea.KeyMsg); isKeyMsg {
 				m.err = nil
@@ -71,15 +114,65 @@ func (m *bendayStartModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, nil
 		}
 
+		if keyMsg, isKeyMsg := msg.(tea.KeyMsg); isKeyMsg {
+			if m.deleteErr != nil {
+				m.deleteErr = nil
+				return m, nil
+			}
+
+			if m.deletingFile != "" {
+				filePath := m.deletingFile
+				m.deletingFile = ""
+
+				if keyMsg.String() != "y" {
+					return m, nil
+				}
+
+				if err := os.Remove(filePath); err != nil {
+					m.deleteErr = err
+					return m, nil
+				}
+
+				return m, m.filePicker.Init()
+			}
+
+			// Only the preview picker deletes. Neither key is a rune, so filters can be typed freely.
+			if key := keyMsg.String(); (key == "delete" || key == "ctrl+d") && m.selectingFile {
+				if filePath, ok := m.filePicker.highlightedFile(); ok {
+					m.deletingFile = filePath
+				}
+
+				return m, nil
+			}
+		}
+
+		directoryBefore := m.filePicker.CurrentDirectory
+
 		var cmd tea.Cmd
 		m.filePicker, cmd = m.filePicker.Update(msg)
 
+		if m.filePicker.CurrentDirectory != directoryBefore {
+			saveLastDirectory(m.filePicker.CurrentDirectory)
+		}
+
 		if didSelect, filePath := m.filePicker.DidSelectFile(msg); didSelect {
+			saveLastDirectory(filepath.Dir(filePath))
+
 			if m.selectingFile {
 				newPreview := newPreviewArtModel(filePath)
 				return newPreview, newPreview.Init()
 			}
 
+			if m.selectingProject {
+				projectModel := newProjectModel(filePath)
+				return projectModel, projectModel.Init()
+			}
+
+			if m.selectingArchive {
+				archiveModel := newArchiveModel(filePath)
+				return archiveModel, archiveModel.Init()
+			}
+
 			if m.importingFile {
 				file, err := os.Open(filePath)
 				if err != nil {
@@ -89,13 +182,39 @@ func (m *bendayStartModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 				defer file.Close()
 
-				pixels, err := importPixelData(file)
+				pixels, stripped, err := importPixelData(file, forceImport)
 				if err != nil {
 					m.err = err
 					return m, nil
 				}
 
 				importModel := newImportCanvasModel(pixels)
+				importModel.warning = strippedRunesWarning(stripped)
+
+				return importModel, importModel.Init()
+			}
+
+			if m.importingImage {
+				file, err := os.Open(filePath)
+				if err != nil {
+					m.err = FileDoesNotExistError
+					return m, nil
+				}
+
+				defer file.Close()
+
+				img, _, err := image.Decode(file)
+				if err != nil {
+					m.err = err
+					return m, nil
+				}
+
+				importModel, err := importCanvasModelFromImage(img)
+				if err != nil {
+					m.err = err
+					return m, nil
+				}
+
 				return importModel, importModel.Init()
 			}
 		}
@@ -103,17 +222,60 @@ func (m *bendayStartModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, cmd
 	}
 
+	if msg, isKeyMsg := msg.(tea.KeyMsg); isKeyMsg {
+		if msg.Paste {
+			filePath, isPlausible := droppedFilePath(string(msg.Runes))
+			if !isPlausible {
+				return m, nil
+			}
+
+			m.droppedFile = ""
+			m.dropErr = validateBendayFile(filePath)
+
+			if m.dropErr == nil {
+				m.droppedFile = filePath
+			}
+
+			return m, nil
+		}
+
+		if m.dropErr != nil {
+			m.dropErr = nil
+			return m, nil
+		}
+
+		if m.pasteErr != nil {
+			m.pasteErr = nil
+			return m, nil
+		}
+
+		if m.droppedFile != "" {
+			filePath := m.droppedFile
+			m.droppedFile = ""
+
+			switch msg.String() {
+			case "y", "enter":
+				newPreview := newPreviewArtModel(filePath)
+				return newPreview, newPreview.Init()
+			}
+
+			return m, nil
+		}
+	}
+
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
 		switch msg.String() {
+		case "?":
+			return newHelpOverlay(m), nil
 		case "tab", "down", "ctrl+n", "j":
-			m.focusedOpt = (m.focusedOpt + 1) % 4
+			m.focusedOpt = (m.focusedOpt + 1) % len(startMenuOptions)
 
 		case "shift+tab", "up", "ctrl+p", "k":
 			m.focusedOpt -= 1
 
 			if m.focusedOpt < 0 {
-				m.focusedOpt = 3
+				m.focusedOpt = len(startMenuOptions) - 1
 			}
 
 		case "enter":
@@ -123,7 +285,7 @@ func (m *bendayStartModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				return newModel, newModel.Init()
 			case 1:
 				m.selectingFile = true
-				m.filePicker.AllowedTypes = []string{".by.png"}
+				m.filePicker.AllowedTypes = []string{".by.png", brailleTextExtension}
 
 				return m, m.filePicker.Init()
 			case 2:
@@ -131,6 +293,35 @@ func (m *bendayStartModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.filePicker.AllowedTypes = []string{".txt"}
 
 				return m, m.filePicker.Init()
+			case 3:
+				m.importingImage = true
+				m.filePicker.AllowedTypes = importImageExtensions
+
+				return m, m.filePicker.Init()
+			case 4:
+				pixels, stripped, err := pasteClipboardPixels()
+				if err != nil {
+					m.pasteErr = err
+					return m, nil
+				}
+
+				importModel := newImportCanvasModel(pixels)
+				importModel.warning = strippedRunesWarning(stripped)
+
+				return importModel, importModel.Init()
+			case 5:
+				m.selectingProject = true
+				m.filePicker.AllowedTypes = []string{projectFileSuffix}
+
+				return m, m.filePicker.Init()
+			case 6:
+				m.selectingArchive = true
+				m.filePicker.AllowedTypes = archiveExtensions
+
+				return m, m.filePicker.Init()
+			case 7:
+				recentModel := newRecentModel()
+				return recentModel, recentModel.Init()
 			default:
 				return m, tea.Quit
 			}
@@ -140,15 +331,115 @@ func (m *bendayStartModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
-func importPixelData(brailleAsciiFile *os.File) ([][]rune, error) {
+func (m *bendayStartModel) isPickingFile() bool {
+	return m.selectingFile || m.importingFile || m.importingImage || m.selectingProject || m.selectingArchive
+}
+
+var (
+	ClipboardUnsupportedError = errors.New("No clipboard available on this system.")
+	ClipboardEmptyError       = errors.New("The clipboard is empty, copy some braille art first.")
+)
+
+// Reads the clipboard like a braille text file, for art copied from a chat or a web page.
+func pasteClipboardPixels() ([][]rune, int, error) {
+	if clipboard.Unsupported {
+		return nil, 0, ClipboardUnsupportedError
+	}
+
+	text, err := clipboard.ReadAll()
+	if err != nil {
+		return nil, 0, fmt.Errorf("Cannot read the clipboard: %v", err)
+	}
+
+	if strings.TrimSpace(text) == "" {
+		return nil, 0, ClipboardEmptyError
+	}
+
+	return importPixelData(strings.NewReader(text), forceImport)
+}
+
+// Terminals paste dropped files in different ways: quoted, with escaped
+// spaces, or as a file:// URL.
+func droppedFilePath(pasted string) (string, bool) {
+	filePath := strings.TrimSpace(pasted)
+	if strings.ContainsRune(filePath, '\n') {
+		return "", false
+	}
+
+	if len(filePath) >= 2 {
+		first, last := filePath[0], filePath[len(filePath)-1]
+		if (first == '\'' || first == '"') && first == last {
+			filePath = filePath[1 : len(filePath)-1]
+		}
+	}
+
+	if fileURL, err := url.Parse(filePath); err == nil && fileURL.Scheme == "file" {
+		filePath = fileURL.Path
+	}
+
+	filePath = strings.ReplaceAll(filePath, "\\ ", " ")
+
+	if !filepath.IsAbs(filePath) || !strings.HasSuffix(filePath, ".by.png") {
+		return "", false
+	}
+
+	return filepath.Clean(filePath), true
+}
+
+func validateBendayFile(filePath string) error {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return FileDoesNotExistError
+	}
+
+	file.Close()
+
+	paddingX, paddingY, err := readCanvasPadding(filePath)
+	if err != nil {
+		return err
+	}
+
+	_, err = getCanvasMeasurement(filePath, paddingX, paddingY)
+	return err
+}
+
+// Set from the --force flag, to import text that is mostly not braille anyway.
+var forceImport = false
+
+// Returned by importPixelData when most of the text is not braille, as with a file picked by mistake.
+type NotBrailleTextE struct {
+	stripped int
+	total    int
+}
+
+func (err NotBrailleTextE) Error() string {
+	return fmt.Sprintf(
+		"%v of the %v characters are not braille, is this the right file? (pass --force to import it anyway)",
+		err.stripped,
+		err.total,
+	)
+}
+
+// Returns the braille characters and how many other characters were left out.
+// Spaces are kept as blank characters, while carriage returns and byte order marks are
+// left out without counting.
+func importPixelData(brailleAsciiFile io.Reader, force bool) ([][]rune, int, error) {
 	pixels := [][]rune{}
 	scanner := bufio.NewScanner(brailleAsciiFile)
 
+	stripped, total := 0, 0
+
 	maxLen := -1
 	for scanner.Scan() {
 		brailleLine := scanner.Text()
 		brailleLine = strings.Map(func(r rune) rune {
-			if isBraille(r) {
+			if r == '\r' || r == '\uFEFF' {
+				return -1
+			}
+
+			total += 1
+
+			if convert.IsBraille(r) {
 				return r
 			}
 
@@ -156,6 +447,7 @@ func importPixelData(brailleAsciiFile *os.File) ([][]rune, error) {
 				return r
 			}
 
+			stripped += 1
 			return -1
 		}, brailleLine)
 
@@ -166,11 +458,15 @@ func importPixelData(brailleAsciiFile *os.File) ([][]rune, error) {
 	}
 
 	if err := scanner.Err(); err != nil {
-		return nil, err
+		return nil, stripped, err
+	}
+
+	if stripped*2 > total && !force {
+		return nil, stripped, NotBrailleTextE{stripped, total}
 	}
 
 	if len(pixels) == 0 {
-		return nil, fmt.Errorf("No data received.")
+		return nil, stripped, fmt.Errorf("No data received.")
 	}
 
 	linesAreEmpty := true
@@ -182,7 +478,7 @@ func importPixelData(brailleAsciiFile *os.File) ([][]rune, error) {
 	}
 
 	if linesAreEmpty {
-		return nil, fmt.Errorf("No data received.")
+		return nil, stripped, fmt.Errorf("No data received.")
 	}
 
 	for i := range pixels {
@@ -190,23 +486,49 @@ func importPixelData(brailleAsciiFile *os.File) ([][]rune, error) {
 		for range maxLen - len(line) {
 			line = append(line, '⠀')
 		}
+
+		pixels[i] = line
 	}
 
-	return pixels, nil
+	return pixels, stripped, nil
+}
+
+var startMenuOptions = [...]string{
+	"Create a new file",
+	"View a benday png",
+	"Import a braille ascii file",
+	"Import an image file",
+	"Paste braille from the clipboard",
+	"Open a project",
+	"Browse an archive",
+	"Open recent",
+	"Exit",
 }
 
 func (m *bendayStartModel) View() string {
-	if m.selectingFile || m.importingFile {
+	if m.isPickingFile() {
 		commandText := "previewing file"
+		if m.selectingProject {
+			commandText = "opening project"
+		}
+
+		if m.selectingArchive {
+			commandText = "opening archive"
+		}
 
-		if m.importingFile {
+		if m.importingFile || m.importingImage {
 			commandText = "importing file"
 
+			errorTitle := "Error importing the braille text file:"
+			if m.importingImage {
+				errorTitle = "Error importing the image file:"
+			}
+
 			if m.err != nil {
 				return lipgloss.JoinVertical(
 					lipgloss.Left,
 					"",
-					"Error importing the braille text file:",
+					errorTitle,
 					m.err.Error(),
 					"",
 					"(import failed) (any key to go back)",
@@ -214,23 +536,35 @@ func (m *bendayStartModel) View() string {
 			}
 		}
 
+		filterText := "filter: (type to filter)"
+		if m.selectingFile {
+			filterText = "filter: (type to filter, delete or ctrl+d to delete the selected file)"
+		}
+
+		if m.filePicker.filter != "" {
+			filterText = fmt.Sprintf("filter: \"%v\" (%v matching)", m.filePicker.filter, len(m.filePicker.matches))
+		}
+
+		if m.deletingFile != "" {
+			filterText = fmt.Sprintf("delete %v? (y/n)", filepath.Base(m.deletingFile))
+		}
+
+		if m.deleteErr != nil {
+			filterText = fmt.Sprintf("Cannot delete the file: %v (any key to continue)", m.deleteErr)
+		}
+
 		return lipgloss.JoinVertical(
 			lipgloss.Left,
 			"",
 			m.filePicker.View(),
 			"",
-			fmt.Sprintf("(%v) (esc to go back, up/down to select file, left/backspace to go back one directory)", commandText),
+			fmt.Sprintf("(%v) (esc to clear filter or go back, up/down to select file, left/backspace to go back one directory)", commandText),
 			fmt.Sprintf("path: \"%v\"", m.filePicker.CurrentDirectory),
+			filterText,
 		)
 	}
 
-	options := [4]string{
-		"Create a new file",
-		"View a benday png",
-		"Import a braille ascii file",
-		"Exit",
-	}
-
+	options := startMenuOptions
 	for i, option := range options {
 		selectedStr := " "
 		if m.focusedOpt == i {
@@ -240,17 +574,45 @@ func (m *bendayStartModel) View() string {
 		options[i] = fmt.Sprintf("  [%v] %v", selectedStr, option)
 	}
 
+	tooltipText := "(up/down to select, enter to confirm, ? for help, esc/ctrl-c to exit program)"
+	if m.droppedFile != "" {
+		tooltipText = lipgloss.JoinVertical(
+			lipgloss.Left,
+			"  Open the dropped file?",
+			fmt.Sprintf("  \"%v\"", m.droppedFile),
+			"",
+			"(opening dropped file) (y/enter to confirm, any other key to go back)",
+		)
+	}
+
+	if m.dropErr != nil {
+		tooltipText = lipgloss.JoinVertical(
+			lipgloss.Left,
+			"  Cannot open the dropped file:",
+			fmt.Sprintf("  %v", m.dropErr),
+			"",
+			"(opening dropped file failed) (any key to go back)",
+		)
+	}
+
+	if m.pasteErr != nil {
+		tooltipText = lipgloss.JoinVertical(
+			lipgloss.Left,
+			"  Cannot paste braille from the clipboard:",
+			fmt.Sprintf("  %v", m.pasteErr),
+			"",
+			"(pasting failed) (any key to go back)",
+		)
+	}
+
 	return lipgloss.JoinVertical(
 		lipgloss.Left,
 		"",
 		"  Benday (github.com/noAbbreviation/benday)",
 		"",
-		options[0],
-		options[1],
-		options[2],
-		options[3],
+		strings.Join(options[:], "\n"),
 		"",
-		"(up/down to select, enter to confirm, esc/ctrl-c to exit program)",
+		tooltipText,
 		"",
 	)
 }
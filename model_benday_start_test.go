@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestDeleteKeyLeavesTheFilterAlone(t *testing.T) {
+	directory := t.TempDir()
+
+	fileName := filepath.Join(directory, "drawing.by.png")
+	if err := os.WriteFile(fileName, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	m := newBendayStartModel()
+	m.selectingFile = true
+	m.filePicker.CurrentDirectory = directory
+
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("d")})
+
+	if m.deletingFile != "" || m.filePicker.filter != "d" {
+		t.Fatalf("typing d asked to delete %q with the filter %q, want it filtered", m.deletingFile, m.filePicker.filter)
+	}
+
+	m.Update(tea.KeyMsg{Type: tea.KeyCtrlD})
+	if m.deletingFile != fileName {
+		t.Fatalf("ctrl+d asked to delete %q, want %q", m.deletingFile, fileName)
+	}
+
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")})
+	if _, err := os.Stat(fileName); !os.IsNotExist(err) {
+		t.Errorf("confirming the delete left the file, %v", err)
+	}
+}
+
+func TestImportPixelData(t *testing.T) {
+	tests := []struct {
+		name         string
+		input        string
+		force        bool
+		want         [][]rune
+		wantStripped int
+		wantErr      string
+	}{
+		{"braille", "⠁⠂\n⠃⠄\n", false, [][]rune{{'⠁', '⠂'}, {'⠃', '⠄'}}, 0, ""},
+		{"ragged lines", "⠁\n⠁⠂⠃\n\n⠂⠂", false, [][]rune{{'⠁', '⠀', '⠀'}, {'⠁', '⠂', '⠃'}, {'⠀', '⠀', '⠀'}, {'⠂', '⠂', '⠀'}}, 0, ""},
+		{"spaces kept", "⠁ ⠂", false, [][]rune{{'⠁', ' ', '⠂'}}, 0, ""},
+		{"carriage returns and byte order marks", "\uFEFF⠁\r\n⠂\r\n", false, [][]rune{{'⠁'}, {'⠂'}}, 0, ""},
+		{"exactly half stripped", "a⠁\nb⠂", false, [][]rune{{'⠁'}, {'⠂'}}, 2, ""},
+		{"over half stripped", "ab⠁", false, nil, 2, NotBrailleTextE{2, 3}.Error()},
+		{"over half stripped, forced", "ab⠁", true, [][]rune{{'⠁'}}, 2, ""},
+		{"empty", "", false, nil, 0, "No data received."},
+		{"empty lines", "\n\n", false, nil, 0, "No data received."},
+		{"nothing but stripped characters, forced", "abc", true, nil, 3, "No data received."},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			pixels, stripped, err := importPixelData(strings.NewReader(test.input), test.force)
+
+			if gotErr := fmt.Sprint(err); err != nil || test.wantErr != "" {
+				if gotErr != test.wantErr {
+					t.Fatalf("got the error %q, want %q", gotErr, test.wantErr)
+				}
+			}
+
+			if stripped != test.wantStripped {
+				t.Errorf("stripped %v characters, want %v", stripped, test.wantStripped)
+			}
+
+			if !slices.EqualFunc(pixels, test.want, slices.Equal) {
+				t.Errorf("got %q, want %q", pixels, test.want)
+			}
+		})
+	}
+}
+
+func TestImportPixelDataPadsRaggedLines(t *testing.T) {
+	art := "⣿⣿⣿⣿\n⣿\n\n⣿⣿⣿⣿⣿⣿\n⣿⣿ ⣿\n"
+
+	pixels, _, err := importPixelData(strings.NewReader(art), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(pixels) != 5 {
+		t.Fatalf("got %v rows, want 5", len(pixels))
+	}
+
+	for y, line := range pixels {
+		if len(line) != 6 {
+			t.Errorf("row %v has %v characters, want 6", y+1, len(line))
+		}
+	}
+}
@@ -3,7 +3,6 @@ package main
 import (
 	"errors"
 	"fmt"
-	"image"
 	"os"
 	"strconv"
 	"strings"
@@ -11,10 +10,7 @@ import (
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
-
-	"image/color"
-	"image/draw"
-	"image/png"
+	"github.com/noAbbreviation/benday/convert"
 )
 
 var (
@@ -26,9 +22,12 @@ var (
 )
 
 type createCanvasModel struct {
-	inputs  *[5]textinput.Model
-	focused int
-	err     error
+	inputs     *[5]textinput.Model
+	focused    int
+	template   int
+	background convert.CanvasBackground
+	align      convert.DotAlignment
+	err        error
 
 	showConfirmPrompt bool
 }
@@ -41,6 +40,21 @@ const (
 	fileNameInputC
 )
 
+type canvasTemplate struct {
+	name   string
+	charsX int
+	charsY int
+}
+
+// The first template is the size from the config file, if any.
+var canvasTemplates = []canvasTemplate{
+	{"defaults", 0, 0},
+	{"icon 16x16", 16, 16},
+	{"square 32x32", 32, 32},
+	{"banner 60x8", 60, 8},
+	{"terminal 80x24", 80, 24},
+}
+
 func newCreateCanvasModel() *createCanvasModel {
 	inputs := [5]textinput.Model{}
 
@@ -51,7 +65,7 @@ func newCreateCanvasModel() *createCanvasModel {
 	inputs[brailleWInputC].Width = 7
 	inputs[brailleWInputC].Prompt = ""
 	inputs[brailleWInputC].Validate = isWholeNumber
-	inputs[brailleWInputC].SetValue("")
+	inputs[brailleWInputC].SetValue(sizeInputValue(defaultCharsX))
 
 	inputs[brailleHInputC] = textinput.New()
 	inputs[brailleHInputC].Placeholder = ""
@@ -59,6 +73,7 @@ func newCreateCanvasModel() *createCanvasModel {
 	inputs[brailleHInputC].Width = 7
 	inputs[brailleHInputC].Prompt = ""
 	inputs[brailleHInputC].Validate = isWholeNumber
+	inputs[brailleHInputC].SetValue(sizeInputValue(defaultCharsY))
 
 	inputs[paddingXInputC] = textinput.New()
 	inputs[paddingXInputC].Placeholder = ""
@@ -66,14 +81,14 @@ func newCreateCanvasModel() *createCanvasModel {
 	inputs[paddingXInputC].Width = 5
 	inputs[paddingXInputC].Prompt = ""
 	inputs[paddingXInputC].Validate = isValidPadding
-	inputs[paddingXInputC].SetValue("0")
+	inputs[paddingXInputC].SetValue(strconv.Itoa(defaultPaddingX))
 
 	inputs[paddingYInputC] = textinput.New()
 	inputs[paddingYInputC].Placeholder = ""
 	inputs[paddingYInputC].CharLimit = 2
 	inputs[paddingYInputC].Width = 5
 	inputs[paddingYInputC].Prompt = ""
-	inputs[paddingYInputC].SetValue("2")
+	inputs[paddingYInputC].SetValue(strconv.Itoa(defaultPaddingY))
 	inputs[paddingYInputC].Validate = isValidPadding
 
 	inputs[fileNameInputC] = textinput.New()
@@ -158,6 +173,8 @@ func (m *createCanvasModel) View() string {
 
 	canvasForm := lipgloss.JoinVertical(
 		lipgloss.Left,
+		fmt.Sprintf("  Template: %v (ctrl+t to change)", canvasTemplates[m.template].name),
+		"",
 		fmt.Sprintf("%v Width(in braille characters): %s", valid[brailleWInputC], m.inputs[brailleWInputC].View()),
 		"",
 		fmt.Sprintf("%v Height(in braille characters): %s", valid[brailleHInputC], m.inputs[brailleHInputC].View()),
@@ -167,11 +184,15 @@ func (m *createCanvasModel) View() string {
 		fmt.Sprintf("%v Image padding Y(in braille dots): %s", valid[paddingYInputC], m.inputs[paddingYInputC].View()),
 		"",
 		fmt.Sprintf("%v File name prefix: %s", valid[fileNameInputC], m.inputs[fileNameInputC].View()),
+		"",
+		fmt.Sprintf("  Background: %v (ctrl+b to change)", m.background),
+		"",
+		fmt.Sprintf("  Dots in the padding: %v (ctrl+o to change)", m.align),
 	)
 
 	canvasPreview := lipgloss.JoinHorizontal(
 		lipgloss.Center,
-		previewBorder.Render(m.previewCanvas()),
+		lipgloss.JoinVertical(lipgloss.Center, previewBorder.Render(m.previewCanvas()), m.sizeText()),
 		" ",
 		canvasForm,
 	)
@@ -191,10 +212,10 @@ func (m *createCanvasModel) View() string {
 func (m *createCanvasModel) promptText() string {
 	if !m.showConfirmPrompt {
 		if m.focused == len(m.inputs)-1 {
-			return "(create new canvas) (enter to continue, up/down to navigate, ctrl-c to exit program, esc to go back)"
+			return "(create new canvas) (enter to continue, up/down to navigate, ? for help, ctrl-c to exit program, esc to go back)"
 		}
 
-		return "(create new canvas) (up/down to navigate, ctrl-c to exit program, esc to go back)"
+		return "(create new canvas) (up/down to navigate, ? for help, ctrl-c to exit program, esc to go back)"
 	}
 
 	hasError := false
@@ -224,11 +245,42 @@ func (m *createCanvasModel) promptText() string {
 		lipgloss.Left,
 		"  Are you sure you want to create this file?",
 		fmt.Sprintf("  \"%v\"", m.fileName()),
+		fmt.Sprintf("  %v", m.sizeText()),
 		"",
 		"(create new canvas) (y/enter to confirm, b/esc to go back)",
 	)
 }
 
+// Empty until every size and padding input is valid.
+func (m createCanvasModel) sizeText() string {
+	for _, inputI := range []int{brailleWInputC, brailleHInputC, paddingXInputC, paddingYInputC} {
+		if m.inputs[inputI].Err != nil {
+			return ""
+		}
+	}
+
+	brailleCharsW, _ := strconv.Atoi(m.inputs[brailleWInputC].Value())
+	brailleCharsH, _ := strconv.Atoi(m.inputs[brailleHInputC].Value())
+	imageWidth, imageHeight := m.imageSize()
+
+	return fmt.Sprintf("%v×%v chars, %v×%v px", brailleCharsW, brailleCharsH, imageWidth, imageHeight)
+}
+
+// Invalid inputs count as 0.
+func (m createCanvasModel) imageSize() (int, int) {
+	brailleCharsW, _ := strconv.Atoi(m.inputs[brailleWInputC].Value())
+	brailleCharsH, _ := strconv.Atoi(m.inputs[brailleHInputC].Value())
+	paddingX, _ := strconv.Atoi(m.inputs[paddingXInputC].Value())
+	paddingY, _ := strconv.Atoi(m.inputs[paddingYInputC].Value())
+
+	return brailleCharsW * (paddingX + convert.BRAILLE_WIDTH), brailleCharsH * (paddingY + newCanvasCellH)
+}
+
+func (m createCanvasModel) pixelCount() int {
+	imageWidth, imageHeight := m.imageSize()
+	return imageWidth * imageHeight
+}
+
 func (m createCanvasModel) previewCanvas() string {
 	var brailleCharsW int
 	var brailleCharsH int
@@ -269,20 +321,39 @@ func (m createCanvasModel) previewCanvas() string {
 		return builder.String()
 	}
 
-	{
-		for range brailleCharsH - 1 {
-			for range brailleCharsW {
-				builder.WriteRune('⣿')
-			}
-			builder.WriteRune('\n')
-		}
+	paddingX, paddingY := 0, 0
+	if m.inputs[paddingXInputC].Err == nil {
+		paddingX, _ = strconv.Atoi(m.inputs[paddingXInputC].Value())
+	}
 
-		for range brailleCharsW {
-			builder.WriteRune('⣿')
+	if m.inputs[paddingYInputC].Err == nil {
+		paddingY, _ = strconv.Atoi(m.inputs[paddingYInputC].Value())
+	}
+
+	// Every character's worth of padding dots, rounded up, is a blank column or row
+	// next to each ⣿, on the side the padding is on.
+	gapX := (paddingX + convert.BRAILLE_WIDTH - 1) / convert.BRAILLE_WIDTH
+	gapY := (paddingY + newCanvasCellH - 1) / newCanvasCellH
+	before := m.align.Offset(gapX, gapY)
+
+	cell := strings.Repeat(" ", before.X) + "⣿" + strings.Repeat(" ", gapX-before.X)
+	line := strings.Repeat(cell, brailleCharsW)
+	blankLine := strings.Repeat(" ", brailleCharsW*(gapX+1))
+
+	lines := []string{}
+	for range brailleCharsH {
+		for range before.Y {
+			lines = append(lines, blankLine)
 		}
 
-		return builder.String()
+		lines = append(lines, line)
+
+		for range gapY - before.Y {
+			lines = append(lines, blankLine)
+		}
 	}
+
+	return strings.Join(lines, "\n")
 }
 
 func (m *createCanvasModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -303,6 +374,30 @@ func (m *createCanvasModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 			startingModel := newBendayStartModel()
 			return startingModel, startingModel.Init()
+		case "ctrl+b":
+			if !m.showConfirmPrompt {
+				m.background = (m.background + 1) % 3
+			}
+
+			return m, nil
+		case "ctrl+t":
+			if !m.showConfirmPrompt {
+				m.template = (m.template + 1) % len(canvasTemplates)
+				m.applyTemplate()
+			}
+
+			return m, nil
+		case "ctrl+o":
+			if !m.showConfirmPrompt {
+				m.align = (m.align + 1) % 3
+			}
+
+			return m, nil
+		case "?":
+			// A file name may contain a question mark.
+			if m.showConfirmPrompt || m.focused != fileNameInputC {
+				return newHelpOverlay(m), nil
+			}
 		}
 	}
 
@@ -392,6 +487,19 @@ func (m *createCanvasModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, tea.Batch(cmds...)
 }
 
+// Only the size is filled in, so the padding and name typed so far are kept.
+func (m *createCanvasModel) applyTemplate() {
+	template := canvasTemplates[m.template]
+
+	charsX, charsY := template.charsX, template.charsY
+	if m.template == 0 {
+		charsX, charsY = defaultCharsX, defaultCharsY
+	}
+
+	m.inputs[brailleWInputC].SetValue(sizeInputValue(charsX))
+	m.inputs[brailleHInputC].SetValue(sizeInputValue(charsY))
+}
+
 func (m *createCanvasModel) prevItem() {
 	m.focused -= 1
 
@@ -404,9 +512,20 @@ func (m *createCanvasModel) nextItem() {
 	m.focused = (m.focused + 1) % (len(m.inputs))
 }
 
+// New canvases larger than this are refused, so a mistyped size cannot exhaust the memory.
+// Set from the --max-pixels flag.
+var maxCanvasPixels = 16_000_000
+
 func (m createCanvasModel) createFile() error {
 	fileName := m.fileName()
 
+	if pixelCount := m.pixelCount(); pixelCount > maxCanvasPixels {
+		return fmt.Errorf(
+			"The canvas would have %v pixels, over the limit of %v. Lower the size, or raise the limit with --max-pixels.",
+			pixelCount, maxCanvasPixels,
+		)
+	}
+
 	_, err := os.Stat(fileName)
 	if err == nil {
 		return fmt.Errorf("File already exists.")
@@ -441,99 +560,15 @@ func (m createCanvasModel) createFile() error {
 		return fmt.Errorf("Invalid input on file name prefix: %v", err)
 	}
 
-	brailleCharsW, _ := strconv.Atoi(m.inputs[brailleWInputC].Value())
-	brailleCharsH, _ := strconv.Atoi(m.inputs[brailleHInputC].Value())
 	paddingX, _ := strconv.Atoi(m.inputs[paddingXInputC].Value())
 	paddingY, _ := strconv.Atoi(m.inputs[paddingYInputC].Value())
+	imageWidth, imageHeight := m.imageSize()
 
-	imageWidth := brailleCharsW * (paddingX + BRAILLE_WIDTH)
-	imageHeight := brailleCharsH * (paddingY + BRAILLE_HEIGHT)
-
-	img := newCanvasImage(imageWidth, imageHeight, paddingX, paddingY, false)
+	img := convert.NewCanvasImage(imageWidth, imageHeight, paddingX, paddingY, false, newCanvasCellH, m.background, m.align)
 
-	encodeErr := png.Encode(file, img)
+	encodeErr := encodeCanvas(file, img, paddingX, paddingY, newCanvasCellH, m.background, m.align, baseReadOptions.Ink)
 	return encodeErr
 }
 
-func newCanvasImage(imageWidth int, imageHeight int, paddingX int, paddingY int, unpadded bool) draw.Image {
-	whiteImage := image.Uniform{color.NRGBA{0xff, 0xff, 0xff, 0xff}}
-
-	img := image.NewNRGBA(image.Rect(0, 0, imageWidth, imageHeight))
-	draw.Draw(img, img.Bounds(), &whiteImage, image.Point{}, draw.Src)
-
-	colorGray := color.NRGBA{R: 0xcc, G: 0xcc, B: 0xcc, A: 0xff}
-	paintWhiteStart := true
-
-	braillePaddedW := paddingX + BRAILLE_WIDTH
-	braillePaddedH := paddingY + BRAILLE_HEIGHT
-
-	if unpadded {
-		braillePaddedW = BRAILLE_WIDTH
-		braillePaddedH = BRAILLE_HEIGHT
-	}
-
-	for bigYOff := 0; bigYOff < imageHeight; bigYOff += braillePaddedH {
-		grayPainterOffsetX := 0
-		if paintWhiteStart {
-			grayPainterOffsetX += braillePaddedW
-		}
-
-		for bigXOff := grayPainterOffsetX; bigXOff < imageWidth; bigXOff += 2 * braillePaddedW {
-			for charYOff := 0; charYOff < BRAILLE_HEIGHT; charYOff += 1 {
-				for charXOff := 0; charXOff < BRAILLE_WIDTH; charXOff += 1 {
-					x := bigXOff + charXOff
-					y := bigYOff + charYOff
-
-					img.SetNRGBA(x, y, colorGray)
-				}
-			}
-		}
-
-		paintWhiteStart = !paintWhiteStart
-	}
-
-	finalImage := draw.Image(img)
-	if unpadded {
-		transparentImg := image.NewUniform(color.NRGBA{})
-
-		verticalRect := image.Rect(imageWidth-1, 0, imageWidth, imageHeight)
-		horizontalRect := image.Rect(0, imageHeight-1, imageWidth, imageHeight)
-
-		draw.Draw(finalImage, verticalRect, transparentImg, image.Point{}, draw.Src)
-		draw.Draw(finalImage, horizontalRect, transparentImg, image.Point{}, draw.Src)
-	} else {
-		finalImage = drawPadding(finalImage, paddingX, paddingY)
-	}
-
-	return finalImage
-}
-
-func drawPadding(img draw.Image, paddingX int, paddingY int) draw.Image {
-	braillePaddedW := paddingX + BRAILLE_WIDTH
-	braillePaddedH := paddingY + BRAILLE_HEIGHT
-
-	bounds := img.Bounds().Max
-	charsX := bounds.X / braillePaddedW
-	charsY := bounds.Y / braillePaddedH
-
-	transparentImg := image.NewUniform(color.NRGBA{})
-	if paddingY > 0 {
-		paddingAreaY := image.Rect(0, 0, bounds.X, paddingY)
-
-		for charY := range charsY {
-			translatorP := image.Point{0, charY*braillePaddedH + BRAILLE_HEIGHT}
-			draw.Draw(img, paddingAreaY.Add(translatorP), transparentImg, image.Point{}, draw.Src)
-		}
-	}
-
-	if paddingX > 0 {
-		paddingAreaX := image.Rect(0, 0, paddingX, bounds.Y)
-
-		for charX := range charsX {
-			translatorP := image.Point{charX*braillePaddedW + BRAILLE_WIDTH, 0}
-			draw.Draw(img, paddingAreaX.Add(translatorP), transparentImg, image.Point{}, draw.Src)
-		}
-	}
-
-	return img
-}
+// Dot rows per character of newly created canvases, set from the --six-dot flag.
+var newCanvasCellH = convert.BRAILLE_HEIGHT
@@ -0,0 +1,230 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/filepicker"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Wraps the bubbles filepicker, which cannot filter its entries. While a filter is
+// typed, the matching entries of the current directory are listed here instead.
+type filteredFilePicker struct {
+	filepicker.Model
+
+	filter   string
+	matches  []os.DirEntry
+	selected int
+
+	selectedPath string
+}
+
+func (f filteredFilePicker) Update(msg tea.Msg) (filteredFilePicker, tea.Cmd) {
+	f.selectedPath = ""
+
+	keyMsg, isKeyMsg := msg.(tea.KeyMsg)
+	if !isKeyMsg || keyMsg.Paste {
+		var cmd tea.Cmd
+		f.Model, cmd = f.Model.Update(msg)
+
+		return f, cmd
+	}
+
+	if keyMsg.Type == tea.KeyRunes {
+		f.filter += string(keyMsg.Runes)
+		f.refreshMatches()
+
+		return f, nil
+	}
+
+	if f.filter == "" {
+		var cmd tea.Cmd
+		f.Model, cmd = f.Model.Update(msg)
+
+		return f, cmd
+	}
+
+	switch keyMsg.String() {
+	case "backspace":
+		filterRunes := []rune(f.filter)
+		f.filter = string(filterRunes[:len(filterRunes)-1])
+		f.refreshMatches()
+
+	case "down", "ctrl+n":
+		f.selected = min(f.selected+1, max(len(f.matches)-1, 0))
+
+	case "up", "ctrl+p":
+		f.selected = max(f.selected-1, 0)
+
+	case "enter", "right":
+		if len(f.matches) == 0 {
+			break
+		}
+
+		entry := f.matches[f.selected]
+		entryPath := filepath.Join(f.CurrentDirectory, entry.Name())
+
+		if !isDirectory(entryPath) {
+			f.selectedPath = entryPath
+			break
+		}
+
+		f.CurrentDirectory = entryPath
+		f.ClearFilter()
+
+		// Jumps to the top, as the old selection means nothing in the new directory.
+		f.Model, _ = f.Model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'g'}})
+		return f, f.Model.Init()
+	}
+
+	return f, nil
+}
+
+func (f *filteredFilePicker) ClearFilter() {
+	f.filter = ""
+	f.matches = nil
+	f.selected = 0
+}
+
+func (f filteredFilePicker) DidSelectFile(msg tea.Msg) (bool, string) {
+	if f.selectedPath != "" {
+		return true, f.selectedPath
+	}
+
+	if f.filter != "" {
+		return false, ""
+	}
+
+	return f.Model.DidSelectFile(msg)
+}
+
+// Matching is case insensitive. Names containing the filter come first, then names
+// containing its letters in order.
+func (f *filteredFilePicker) refreshMatches() {
+	f.matches = nil
+	f.selected = 0
+
+	if f.filter == "" {
+		return
+	}
+
+	dirEntries, err := os.ReadDir(f.CurrentDirectory)
+	if err != nil {
+		return
+	}
+
+	filter := strings.ToLower(f.filter)
+	fuzzyMatches := []os.DirEntry{}
+
+	for _, entry := range dirEntries {
+		name := entry.Name()
+		if strings.HasPrefix(name, ".") {
+			continue
+		}
+
+		if !isDirectory(filepath.Join(f.CurrentDirectory, name)) && !f.allowsFile(name) {
+			continue
+		}
+
+		lowerName := strings.ToLower(name)
+		if strings.Contains(lowerName, filter) {
+			f.matches = append(f.matches, entry)
+		} else if isSubsequence(filter, lowerName) {
+			fuzzyMatches = append(fuzzyMatches, entry)
+		}
+	}
+
+	f.matches = append(f.matches, fuzzyMatches...)
+}
+
+func (f filteredFilePicker) allowsFile(name string) bool {
+	return len(f.AllowedTypes) == 0 || slices.ContainsFunc(f.AllowedTypes, func(allowedType string) bool {
+		return strings.HasSuffix(name, allowedType)
+	})
+}
+
+func isDirectory(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
+
+func isSubsequence(sub string, s string) bool {
+	subRunes := []rune(sub)
+	if len(subRunes) == 0 {
+		return true
+	}
+
+	for _, r := range s {
+		if r == subRunes[0] {
+			subRunes = subRunes[1:]
+		}
+
+		if len(subRunes) == 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (f filteredFilePicker) View() string {
+	if f.filter == "" {
+		return f.Model.View()
+	}
+
+	if len(f.matches) == 0 {
+		return f.Styles.EmptyDirectory.Height(f.Height).MaxHeight(f.Height).Render("No matching files.")
+	}
+
+	top := max(f.selected-f.Height+1, 0)
+	bottom := min(top+f.Height, len(f.matches))
+
+	lines := []string{}
+	for i, entry := range f.matches[top:bottom] {
+		style := f.Styles.File
+		if entry.IsDir() {
+			style = f.Styles.Directory
+		}
+
+		if top+i == f.selected {
+			lines = append(lines, f.Styles.Cursor.Render(f.Cursor)+f.Styles.Selected.Render(" "+entry.Name()))
+			continue
+		}
+
+		lines = append(lines, " "+style.Render(" "+entry.Name()))
+	}
+
+	for len(lines) <= f.Height {
+		lines = append(lines, "")
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// The filepicker does not expose its highlighted entry, so a copy of it is asked to select
+// it instead. Returns false for directories and files of other types.
+func (f filteredFilePicker) highlightedFile() (string, bool) {
+	if f.filter != "" {
+		if len(f.matches) == 0 {
+			return "", false
+		}
+
+		entryPath := filepath.Join(f.CurrentDirectory, f.matches[f.selected].Name())
+		return entryPath, !isDirectory(entryPath) && f.allowsFile(entryPath)
+	}
+
+	probe := f.Model
+	probe.Path = ""
+	probe, _ = probe.Update(tea.KeyMsg{Type: tea.KeyEnter})
+
+	if probe.CurrentDirectory != f.CurrentDirectory {
+		// Entering a directory pushes onto a view stack that is shared between copies.
+		probe.Update(tea.KeyMsg{Type: tea.KeyLeft})
+		return "", false
+	}
+
+	return probe.Path, probe.Path != "" && f.allowsFile(probe.Path)
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Screens that open the help overlay with ? and list their keys in it.
+type helpfulModel interface {
+	tea.Model
+	helpText() string
+}
+
+// Shows the keys of the screen it was opened from, and goes back to it on any key.
+// Other messages still reach that screen, so watching and blinking carry on underneath.
+type helpOverlayModel struct {
+	previous helpfulModel
+}
+
+func newHelpOverlay(previous helpfulModel) helpOverlayModel {
+	return helpOverlayModel{previous: previous}
+}
+
+func (_ helpOverlayModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m helpOverlayModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if keyMsg, isKeyMsg := msg.(tea.KeyMsg); isKeyMsg {
+		if keyMsg.String() == "ctrl+c" {
+			return m, tea.Quit
+		}
+
+		return m.previous, nil
+	}
+
+	newModel, cmd := m.previous.Update(msg)
+
+	previous, isHelpful := newModel.(helpfulModel)
+	if !isHelpful {
+		return newModel, cmd
+	}
+
+	m.previous = previous
+	return m, cmd
+}
+
+func (m helpOverlayModel) View() string {
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		"",
+		m.previous.helpText(),
+		"",
+		"(help) (any key to go back, ctrl-c to exit program)",
+		"",
+	)
+}
+
+func formatKeybindings(title string, keybindings [][2]string) string {
+	builder := strings.Builder{}
+	builder.WriteString(title)
+
+	for _, keybinding := range keybindings {
+		builder.WriteString(fmt.Sprintf("\n  %-8v %v", keybinding[0], keybinding[1]))
+	}
+
+	return builder.String()
+}
+
+var startKeybindings = [][2]string{
+	{"up/down", "select a menu option, or a file when picking one"},
+	{"enter", "confirm the selected option or file"},
+	{"type", "filter the files when picking one"},
+	{"d", "delete the selected file when picking one to preview"},
+	{"left", "go back one directory when picking a file, like backspace"},
+	{"esc", "clear the filter, go back, or exit from the menu"},
+	{"?", "show this help (from the menu)"},
+	{"ctrl-c", "exit the program"},
+}
+
+var createKeybindings = [][2]string{
+	{"up/down", "move between the inputs"},
+	{"enter", "continue to the confirmation, or create the canvas when confirming"},
+	{"ctrl+t", "change the template"},
+	{"ctrl+b", "change the background"},
+	{"ctrl+o", "change where the dots sit in padded characters"},
+	{"b", "go back from the confirmation"},
+	{"?", "show this help (except while typing the file name)"},
+	{"esc", "go back"},
+	{"ctrl-c", "exit the program"},
+}
+
+var importKeybindings = [][2]string{
+	{"up/down", "move between the inputs"},
+	{"enter", "continue to the confirmation, or import when confirming"},
+	{"ctrl+o", "change where the dots sit in padded characters"},
+	{"y", "overwrite an existing file when asked"},
+	{"b / n", "go back from the confirmation"},
+	{"?", "show this help (except while typing the file name)"},
+	{"esc", "go back"},
+	{"ctrl-c", "exit the program"},
+}
+
+var listKeybindings = [][2]string{
+	{"up/down", "select an entry"},
+	{"enter", "open the selected entry"},
+	{"?", "show this help"},
+	{"esc", "go back"},
+	{"ctrl-c", "exit the program"},
+}
+
+func (m *bendayStartModel) helpText() string {
+	return formatKeybindings("Keys on the start menu:", startKeybindings)
+}
+
+func (m *createCanvasModel) helpText() string {
+	return formatKeybindings("Keys when creating a canvas:", createKeybindings)
+}
+
+func (m *importCanvasModel) helpText() string {
+	return formatKeybindings("Keys when importing braille ascii:", importKeybindings)
+}
+
+func (m *previewArtModel) helpText() string {
+	return formatKeybindings("Keys when previewing a canvas:", previewKeybindings)
+}
+
+func (m *projectModel) helpText() string {
+	return formatKeybindings("Keys when browsing a project:", listKeybindings)
+}
+
+func (m *archiveModel) helpText() string {
+	return formatKeybindings("Keys when browsing an archive:", listKeybindings)
+}
+
+func (m *recentModel) helpText() string {
+	return formatKeybindings("Keys when browsing recent files:", listKeybindings)
+}
@@ -3,16 +3,13 @@ package main
 import (
 	"fmt"
 	"image"
-	"image/color"
-	"image/png"
 	"os"
-	"slices"
 	"strconv"
-	"strings"
 
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/noAbbreviation/benday/convert"
 )
 
 type importCanvasModel struct {
@@ -22,9 +19,23 @@ type importCanvasModel struct {
 	err     error
 
 	showConfirmPrompt bool
+	overwriting       bool
 	_fromArgs         bool
+
+	fromImage bool
+	warning   string
+
+	align convert.DotAlignment
 }
 
+// Images bigger than this are downscaled before thresholding, so photos stay workable.
+const (
+	maxImageImportCharsX = 80
+	maxImageImportCharsY = 40
+)
+
+var importImageExtensions = []string{".png", ".jpg", ".jpeg", ".gif"}
+
 const (
 	paddingXInputI = iota
 	paddingYInputI = iota
@@ -40,7 +51,7 @@ func newImportCanvasModel(pixels [][]rune) *importCanvasModel {
 	inputs[paddingXInputI].Width = 5
 	inputs[paddingXInputI].Prompt = ""
 	inputs[paddingXInputI].Validate = isValidPadding
-	inputs[paddingXInputI].SetValue("0")
+	inputs[paddingXInputI].SetValue(strconv.Itoa(defaultPaddingX))
 	inputs[paddingXInputI].Focus()
 
 	inputs[paddingYInputI] = textinput.New()
@@ -49,7 +60,7 @@ func newImportCanvasModel(pixels [][]rune) *importCanvasModel {
 	inputs[paddingYInputI].Width = 5
 	inputs[paddingYInputI].Prompt = ""
 	inputs[paddingYInputI].Validate = isValidPadding
-	inputs[paddingYInputI].SetValue("2")
+	inputs[paddingYInputI].SetValue(strconv.Itoa(defaultPaddingY))
 
 	inputs[fileNameInputI] = textinput.New()
 	inputs[fileNameInputI].Placeholder = ""
@@ -72,6 +83,62 @@ func importCanvasModelFromArgs(pixels [][]rune) *importCanvasModel {
 	return model
 }
 
+func strippedRunesWarning(stripped int) string {
+	if stripped == 0 {
+		return ""
+	}
+
+	return fmt.Sprintf("%v characters that are not braille were left out.", stripped)
+}
+
+// Every pixel becomes one dot, shaded like the canvas reads them (see shadeType).
+func importCanvasModelFromImage(img image.Image) (*importCanvasModel, error) {
+	bounds := img.Bounds()
+	maxDotsX := maxImageImportCharsX * convert.BRAILLE_WIDTH
+	maxDotsY := maxImageImportCharsY * newCanvasCellH
+
+	warning := ""
+	if bounds.Dx() > maxDotsX || bounds.Dy() > maxDotsY {
+		img = downscaleImage(img, maxDotsX, maxDotsY)
+		warning = fmt.Sprintf(
+			"The %vx%v image was downscaled to %vx%v dots to fit %vx%v characters.",
+			bounds.Dx(), bounds.Dy(), img.Bounds().Dx(), img.Bounds().Dy(), maxImageImportCharsX, maxImageImportCharsY,
+		)
+	}
+
+	pixels := rasterToPixels(img, newCanvasCellH)
+	if len(pixels) == 0 || len(pixels[0]) == 0 {
+		return nil, fmt.Errorf("The image is empty.")
+	}
+
+	model := newImportCanvasModel(pixels)
+	model.fromImage = true
+	model.warning = warning
+
+	return model, nil
+}
+
+// Nearest-neighbour downscale that keeps the aspect ratio.
+func downscaleImage(img image.Image, maxW int, maxH int) image.Image {
+	bounds := img.Bounds()
+	scale := min(float64(maxW)/float64(bounds.Dx()), float64(maxH)/float64(bounds.Dy()))
+
+	newW := max(int(float64(bounds.Dx())*scale), 1)
+	newH := max(int(float64(bounds.Dy())*scale), 1)
+
+	scaled := image.NewNRGBA(image.Rect(0, 0, newW, newH))
+	for y := range newH {
+		for x := range newW {
+			srcX := bounds.Min.X + (x*bounds.Dx()+bounds.Dx()/2)/newW
+			srcY := bounds.Min.Y + (y*bounds.Dy()+bounds.Dy()/2)/newH
+
+			scaled.Set(x, y, img.At(srcX, srcY))
+		}
+	}
+
+	return scaled
+}
+
 func (m *importCanvasModel) Init() tea.Cmd {
 	return textinput.Blink
 }
@@ -85,6 +152,7 @@ func (m *importCanvasModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case "esc":
 			if m.showConfirmPrompt {
 				m.showConfirmPrompt = false
+				m.overwriting = false
 				m.inputs[m.focused].Focus()
 
 				return m, nil
@@ -96,6 +164,17 @@ func (m *importCanvasModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 			startingModel := newBendayStartModel()
 			return startingModel, startingModel.Init()
+		case "ctrl+o":
+			if !m.showConfirmPrompt {
+				m.align = (m.align + 1) % 3
+			}
+
+			return m, nil
+		case "?":
+			// A file name may contain a question mark.
+			if m.showConfirmPrompt || m.focused != fileNameInputI {
+				return newHelpOverlay(m), nil
+			}
 		}
 	}
 
@@ -111,6 +190,7 @@ func (m *importCanvasModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if hasError || m.err != nil {
 			if _, ok := msg.(tea.KeyMsg); ok {
 				m.showConfirmPrompt = false
+				m.overwriting = false
 				m.inputs[m.focused].Focus()
 				m.err = nil
 
@@ -124,15 +204,25 @@ func (m *importCanvasModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case tea.KeyMsg:
 			switch msg.String() {
 			case "y", "enter":
-				if err := m.createFile(); err != nil {
+				if m.overwriting && msg.String() != "y" {
+					return m, nil
+				}
+
+				if _, err := os.Stat(m.fileName()); err == nil && !m.overwriting {
+					m.overwriting = true
+					return m, nil
+				}
+
+				if err := m.createFile(m.overwriting); err != nil {
 					m.err = err
 					return m, nil
 				}
 
 				previewModel := newPreviewArtModel(m.fileName())
 				return previewModel, previewModel.Init()
-			case "b":
+			case "b", "n":
 				m.showConfirmPrompt = false
+				m.overwriting = false
 				m.inputs[m.focused].Focus()
 				return m, nil
 			}
@@ -195,12 +285,12 @@ func (m *importCanvasModel) fileName() string {
 	return fileName
 }
 
-func (m importCanvasModel) createFile() error {
+func (m importCanvasModel) createFile(overwrite bool) error {
 	fileName := m.fileName()
 
 	_, err := os.Stat(fileName)
-	if err == nil {
-		return fmt.Errorf("File already exists.")
+	if err == nil && !overwrite {
+		return ExportFileExistsError
 	}
 
 	file, err := os.Create(fileName)
@@ -227,52 +317,19 @@ func (m importCanvasModel) createFile() error {
 	paddingX, _ := strconv.Atoi(m.inputs[paddingXInputI].Value())
 	paddingY, _ := strconv.Atoi(m.inputs[paddingYInputI].Value())
 
-	charsX := len(m.pixels[0])
-	charsY := len(m.pixels)
-
-	imageWidth := charsX * (paddingX + BRAILLE_WIDTH)
-	imageHeight := charsY * (paddingY + BRAILLE_HEIGHT)
-
-	img := newCanvasImage(imageWidth, imageHeight, paddingX, paddingY, false).(*image.NRGBA)
-
-	for charY, _line := range m.pixels {
-		for charX, charRune := range _line {
-			brailleBits := []rune(strconv.FormatInt(BrailleReverseLookup(charRune), 2))
-
-			for range BRAILLE_WIDTH*BRAILLE_HEIGHT - len(brailleBits) {
-				brailleBits = append([]rune{'0'}, brailleBits...)
-			}
-			slices.Reverse(brailleBits)
-
-			for brailleYOff := range BRAILLE_HEIGHT {
-				for brailleXOff := range BRAILLE_WIDTH {
-					bitsIdx := brailleYOff*BRAILLE_WIDTH + brailleXOff
-
-					if brailleBits[bitsIdx] != '1' {
-						continue
-					}
-
-					x := charX*(BRAILLE_WIDTH+paddingX) + brailleXOff
-					y := charY*(BRAILLE_HEIGHT+paddingY) + brailleYOff
-
-					colorBlack := color.NRGBA{0x33, 0x33, 0x33, 0xff}
-					img.SetNRGBA(x, y, colorBlack)
-				}
-			}
-		}
-	}
+	img := convert.NewPixelsImage(m.pixels, paddingX, paddingY, newCanvasCellH, m.align, baseReadOptions.Ink)
 
-	encodeErr := png.Encode(file, img)
+	encodeErr := encodeCanvas(file, img, paddingX, paddingY, newCanvasCellH, convert.BackgroundCheckerboard, m.align, baseReadOptions.Ink)
 	return encodeErr
 }
 
 func (m *importCanvasModel) promptText() string {
 	if !m.showConfirmPrompt {
 		if m.focused == len(m.inputs)-1 {
-			return "(importing to benday) (enter to continue, up/down to navigate, ctrl-c to exit program, esc to go back)"
+			return "(importing to benday) (enter to continue, up/down to navigate, ? for help, ctrl-c to exit program, esc to go back)"
 		}
 
-		return "(importing to benday) (up/down to navigate, ctrl-c to exit program, esc to go back)"
+		return "(importing to benday) (up/down to navigate, ? for help, ctrl-c to exit program, esc to go back)"
 	}
 
 	hasError := false
@@ -298,6 +355,16 @@ func (m *importCanvasModel) promptText() string {
 		)
 	}
 
+	if m.overwriting {
+		return lipgloss.JoinVertical(
+			lipgloss.Left,
+			"  The file exists. Overwrite it?",
+			fmt.Sprintf("  \"%v\"", m.fileName()),
+			"",
+			"(importing to benday) (y to overwrite, n/esc to go back)",
+		)
+	}
+
 	return lipgloss.JoinVertical(
 		lipgloss.Left,
 		"  Are you sure you want to create this file?",
@@ -326,31 +393,30 @@ func (m *importCanvasModel) View() string {
 		fmt.Sprintf("%v Image padding Y(in braille dots): %s", valid[paddingYInputI], m.inputs[paddingYInputI].View()),
 		"",
 		fmt.Sprintf("%v File name prefix: %s", valid[fileNameInputI], m.inputs[fileNameInputI].View()),
+		"",
+		fmt.Sprintf("  Dots in the padding: %v (ctrl+o to change)", m.align),
 	)
 
-	previewBuilder := strings.Builder{}
-	for _, pixel := range m.pixels[0] {
-		previewBuilder.WriteRune(pixel)
-	}
-
-	for _, line := range m.pixels[1:] {
-		previewBuilder.WriteRune('\n')
-		for _, pixel := range line {
-			previewBuilder.WriteRune(pixel)
-		}
-	}
-
 	previewCanvas := lipgloss.JoinHorizontal(
 		lipgloss.Center,
-		previewBorder.Render(previewBuilder.String()),
+		previewBorder.Render(pixelsToText(m.pixels)),
 		" ",
 		canvasForm,
 	)
 
+	title := "Import a braille ascii file:"
+	if m.fromImage {
+		title = "Import an image file:"
+	}
+
+	if m.warning != "" {
+		title = lipgloss.JoinVertical(lipgloss.Left, title, fmt.Sprintf("Warning: %v", m.warning))
+	}
+
 	return lipgloss.JoinVertical(
 		lipgloss.Left,
 		"",
-		"Import a braille ascii file:",
+		title,
 		previewCanvas,
 		"",
 		promptText,
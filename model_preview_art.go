@@ -8,15 +8,20 @@ import (
 	"image/color"
 	"image/draw"
 	"image/png"
+	"io"
+	"math/bits"
 	"os"
-	"slices"
+	"path/filepath"
+	"regexp"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/atotto/clipboard"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/noAbbreviation/benday/convert"
 )
 
 var (
@@ -33,60 +38,104 @@ type silentError struct {
 	error
 }
 
-type InvalidImgDimensionE struct {
-	measure           int
-	mustBeDivisibleBy int
-	errorOnX          bool
-
-	isUnpadded bool
-}
-
-func (err InvalidImgDimensionE) Error() string {
-	measureName := "width"
-	if !err.errorOnX {
-		measureName = "height"
-	}
-
-	minusOneText := ""
-	if err.isUnpadded {
-		minusOneText = " - 1"
-	}
-
-	return fmt.Sprintf(
-		"Invalid image dimension. Expected %v%v to be divisible by %v, but is instead %v px.",
-		measureName,
-		minusOneText,
-		err.mustBeDivisibleBy,
-		err.measure,
-	)
-}
-
 type previewArtModel struct {
-	fileName    string
-	writeSignal chan struct{}
+	fileName         string
+	previousFileName string
+	writeSignal      chan struct{}
 
 	processError    error
 	updateViewError error
 
+	// Set while a file that was shown before cannot be read, like when caught halfway through
+	// being saved. The last good render is kept until it reads again.
+	unreadableError error
+
 	paddingX int
 	paddingY int
+	cellH    int
 	pixels   [][]rune
+	colors   [][]color.Color
+
+	measure    convert.CanvasMeasure
+	shadedDots int
+
+	colored     bool
+	spaceBlanks bool
+	heatmap     bool
+	threshold   convert.ShadeThreshold
+	inverted    bool
+
+	undoHistory []canvasSnapshot
 
-	watchTicker bool
-	unpadded    bool
+	// Unsaved edits with --buffer-edits, nil when the file is up to date.
+	buffer *editBuffer
+
+	windowWidth  int
+	windowHeight int
+	viewOffset   image.Point
+	zoom         int
+	showGuides   bool
+	showNumbers  bool
+
+	editing  bool
+	cursor   image.Point
+	symmetry symmetryAxis
+
+	// Spans from the anchor to the cursor, extended with shift+arrows while editing.
+	selecting       bool
+	selectionAnchor image.Point
+
+	cacheKey  previewCacheKey
+	cachedMsg updatePreviewMsg
+
+	watchTicker   bool
+	watchPaused   bool
+	watchInterval time.Duration
+	unpadded      bool
+
+	// What a canvas operation running off the update loop is doing, empty when idle.
+	working string
+
+	confirmingReset bool
+
+	// Set when esc or ctrl+c would throw away a typed file name or a pending resize.
+	confirmingDiscard bool
+	discardQuits      bool
 
 	notifMessage string
 	notifTime    time.Time
 
-	_fromArgs  bool
+	graphics    graphicsProtocol
+	showSource  bool
+	sourceImage string
+
+	_fromArgs    bool
+	_escToMenu   bool
+	_argFiles    []string
+	_argIndex    int
+	thumbnails   map[string][][]rune
+	_fromProject string
+	archivePath  string
+
+	// Set from the --padding flag, for files whose name and metadata lack the padding.
+	paddingOverride *image.Point
+
 	rOpts      resizeOptionStore
 	exportOpts exportOptionStore
+	saveOpts   saveAsOptionStore
+	repadOpts  repadOptionStore
 }
 
 type resizeOptionStore struct {
 	inputs         [2]int
 	toResizeHeight bool
 
+	// Digits typed for the focused axis, as an absolute character count.
+	typedTarget string
+
+	// Which edge stays in place, from 0 (left/top) through 1 (center) to 2 (right/bottom).
+	anchor image.Point
+
 	resizing          bool
 	showConfirmPrompt bool
 }
@@ -94,23 +143,54 @@ type resizeOptionStore struct {
 type exportOptionStore struct {
 	exporting         bool
 	showConfirmPrompt bool
+	contentMode       exportContentMode
+	format            exportFormat
+	scale             int
+	aspect            int
+	keepGrid          bool
+	htmlLines         bool
+	spaceBlanks       bool
+	overwriting       bool
+
+	input textinput.Model
+}
+
+type saveAsOptionStore struct {
+	saving      bool
+	duplicating bool
+	suggestion  string
+	err         error
+
+	input textinput.Model
+}
+
+type repadOptionStore struct {
+	repadding bool
+	err       error
 
 	input textinput.Model
 }
 
-type canvasMeasure struct {
-	imageWidth  int
-	imageHeight int
-	isUnpadded  bool
+type exportContentMode int
 
-	charsX int
-	charsY int
+const (
+	exportWholeCanvas exportContentMode = iota
+	exportContentOnly
+	exportContentWithHeader
+)
 
-	brailleW int
-	brailleH int
+func (mode exportContentMode) String() string {
+	switch mode {
+	case exportContentOnly:
+		return "content only"
+	case exportContentWithHeader:
+		return "content only, with placement header"
+	default:
+		return "whole canvas"
+	}
 }
 
-func newPreviewArtModel(fileName string) *previewArtModel {
+func newFileNameInput() textinput.Model {
 	textInput := textinput.New()
 	textInput.Placeholder = ""
 	textInput.CharLimit = 64
@@ -118,24 +198,107 @@ func newPreviewArtModel(fileName string) *previewArtModel {
 	textInput.Prompt = ""
 	textInput.Validate = isValidFileName
 
+	return textInput
+}
+
+func blankPreviewArtModel(fileName string) *previewArtModel {
 	newModel := &previewArtModel{
-		fileName:    fileName,
-		writeSignal: make(chan struct{}, 1),
+		fileName:      fileName,
+		writeSignal:   make(chan struct{}, 1),
+		graphics:      detectGraphicsProtocol(),
+		threshold:     convert.DefaultShadeThreshold,
+		inverted:      baseReadOptions.Invert,
+		watchInterval: defaultWatchInterval,
+		cellH:         convert.BRAILLE_HEIGHT,
 		exportOpts: exportOptionStore{
-			input: textInput,
+			input: newFileNameInput(),
+			scale: defaultExportScale,
+		},
+		saveOpts: saveAsOptionStore{
+			input: newFileNameInput(),
 		},
+		repadOpts: repadOptionStore{
+			input: newFileNameInput(),
+		},
+	}
+
+	return newModel
+}
+
+// The most recently previewed file, kept across models so the preview can switch back to it.
+var lastPreviewedFile string
+
+func newPreviewArtModel(fileName string) *previewArtModel {
+	newModel := blankPreviewArtModel(fileName)
+	newModel.loadPixels()
+
+	if lastPreviewedFile != fileName {
+		newModel.previousFileName = lastPreviewedFile
 	}
-	pixelData := newModel.GetPixels()
 
-	newModel.pixels = pixelData.pixels
-	newModel.updateViewError = pixelData.err
+	lastPreviewedFile = fileName
+	recordRecentFile(fileName)
+
+	return newModel
+}
+
+func newArchivePreviewArtModel(archivePath string, memberName string) *previewArtModel {
+	newModel := blankPreviewArtModel(memberName)
+	newModel.archivePath = archivePath
+	newModel.loadPixels()
 
 	return newModel
 }
 
-func previewArtModelFromArgs(fileName string) *previewArtModel {
-	previewModel := newPreviewArtModel(fileName)
+func (m *previewArtModel) loadPixels() {
+	m.showPixels(m.GetPixels())
+}
+
+func (m *previewArtModel) showPixels(pixelData updatePreviewMsg) {
+	m.pixels = pixelData.pixels
+	m.colors = pixelData.colors
+	m.updateViewError = pixelData.err
+
+	m.recordThumbnail()
+}
+
+// Files that cannot be opened at all are reported instead of ending the program,
+// so one bad argument does not stop the rest from being cycled through.
+func (m *previewArtModel) switchToFile(fileName string) {
+	if fileName == m.fileName {
+		return
+	}
+
+	fileNameBefore, pixelsBefore, colorsBefore, errBefore := m.fileName, m.pixels, m.colors, m.updateViewError
+
+	m.fileName = fileName
+	m.loadPixels()
+
+	if _, isDecodeError := m.updateViewError.(decodeError); isDecodeError {
+		m.notifTime = time.Now()
+		m.notifMessage = fmt.Sprintf("cannot open %v: %v", fileName, m.updateViewError)
+
+		m.fileName, m.pixels, m.colors, m.updateViewError = fileNameBefore, pixelsBefore, colorsBefore, errBefore
+		return
+	}
+
+	m.previousFileName = fileNameBefore
+	lastPreviewedFile = fileName
+
+	m.sourceImage = ""
+}
+
+func previewArtModelFromArgs(fileNames []string, paddingOverride *image.Point) *previewArtModel {
+	previewModel := newPreviewArtModel(fileNames[0])
 	previewModel._fromArgs = true
+	previewModel._argFiles = fileNames
+	previewModel.recordThumbnail()
+
+	if paddingOverride != nil {
+		previewModel.paddingOverride = paddingOverride
+		previewModel.cacheKey = previewCacheKey{}
+		previewModel.loadPixels()
+	}
 
 	return previewModel
 }
@@ -143,112 +306,324 @@ func previewArtModelFromArgs(fileName string) *previewArtModel {
 func (m *previewArtModel) Init() tea.Cmd {
 	return tea.Batch(
 		textinput.Blink,
-		func() tea.Msg { return m.GetPixels() },
+		m.readPreviewCmd(),
 	)
 }
 
-func (m *previewArtModel) Tick() (*previewArtModel, tea.Cmd) {
-	return m, tea.Every(time.Millisecond*500, func(t time.Time) tea.Msg {
-		if len(m.writeSignal) != 0 {
-			<-m.writeSignal
-		}
+// Set from the --watch-interval flag or the BENDAY_WATCH_INTERVAL environment variable.
+var defaultWatchInterval = time.Millisecond * 500
 
-		m.watchTicker = !m.watchTicker
-		return m.GetPixels()
+// Update decides whether the file is read, the tick itself does not look at the model.
+type watchTickMsg struct{}
+
+// Prompts pass these on instead of feeding them to their input, so watching keeps going.
+func isWatchMsg(msg tea.Msg) bool {
+	switch msg.(type) {
+	case watchTickMsg, updatePreviewMsg:
+		return true
+	}
+
+	return false
+}
+
+func (m *previewArtModel) Tick() (*previewArtModel, tea.Cmd) {
+	return m, tea.Every(m.watchInterval, func(t time.Time) tea.Msg {
+		return watchTickMsg{}
 	})
 }
 
+// Reads the file off the update loop from values copied here, Update takes the result.
+func (m *previewArtModel) readPreviewCmd() tea.Cmd {
+	src, cacheKey, cachedMsg := m.previewSource(), m.cacheKey, m.cachedMsg
+
+	return func() tea.Msg {
+		return readPreview(src, cacheKey, cachedMsg)
+	}
+}
+
 type updatePreviewMsg struct {
 	err    error
 	pixels [][]rune
+	colors [][]color.Color
+	img    image.Image
+
+	// What was read and how it measured, taken by the model in takePreview.
+	source   previewSource
+	cacheKey previewCacheKey
+	measure  convert.CanvasMeasure
+	paddingX int
+	paddingY int
+}
+
+// What the preview reads, copied from the model so the file can be read off the update loop.
+type previewSource struct {
+	fileName        string
+	archivePath     string
+	paddingOverride *image.Point
+	options         convert.ReadOptions
+}
+
+func (m *previewArtModel) previewSource() previewSource {
+	return previewSource{m.fileName, m.archivePath, m.paddingOverride, m.readOptions()}
+}
+
+// Reads the previewed file, or the archive member when previewing an archive.
+func (src previewSource) readCanvas() (decodedCanvas, error) {
+	var file io.ReadCloser
+	var err error
+
+	if src.archivePath != "" {
+		file, err = openArchiveMember(src.archivePath, src.fileName)
+	} else {
+		file, err = os.Open(src.fileName)
+	}
+
+	if err != nil {
+		return decodedCanvas{}, decodeError{FileDoesNotExistError}
+	}
+
+	defer file.Close()
+
+	if isBrailleTextFile(src.fileName) {
+		return decodeBrailleText(file, src.options)
+	}
+
+	if override := src.paddingOverride; override != nil {
+		data, err := io.ReadAll(file)
+		if err != nil {
+			return decodedCanvas{}, decodeError{fmt.Errorf("Error reading the image: %w", err)}
+		}
+
+		return decodeCanvasData(data, override.X, override.Y, src.options)
+	}
+
+	return decodeCanvas(file, src.fileName, src.options)
+}
+
+// Identifies what a decode was made from, so an unchanged file is not decoded again.
+type previewCacheKey struct {
+	fileName    string
+	archivePath string
+	modTime     time.Time
+	size        int64
+	options     convert.ReadOptions
+}
+
+// The preview adjusts the threshold and reading light on dark, the rest comes from the flags.
+func (m *previewArtModel) readOptions() convert.ReadOptions {
+	opts := baseReadOptions
+	opts.Threshold = m.threshold
+	opts.Invert = m.inverted
+
+	return opts
 }
 
 func (model *previewArtModel) GetPixels() updatePreviewMsg {
-	file, err := os.Open(model.fileName)
+	if model.buffer != nil {
+		msg := model.buffer.pixels(model.readOptions())
+		model.shadedDots, _ = inkDelta(nil, msg.pixels)
+
+		return msg
+	}
+
+	msg := readPreview(model.previewSource(), model.cacheKey, model.cachedMsg)
+	model.takePreview(msg)
+
+	return msg
+}
+
+// Returns cachedMsg if the file is unchanged since cacheKey. This does not touch the
+// model, so it is safe to run in a command.
+func readPreview(src previewSource, cacheKey previewCacheKey, cachedMsg updatePreviewMsg) updatePreviewMsg {
+	statPath := src.fileName
+	if src.archivePath != "" {
+		statPath = src.archivePath
+	}
+
+	newCacheKey := previewCacheKey{}
+	if fileStats, err := os.Stat(statPath); err == nil {
+		newCacheKey = previewCacheKey{
+			src.fileName, src.archivePath, fileStats.ModTime(), fileStats.Size(), src.options,
+		}
+
+		if newCacheKey == cacheKey {
+			return cachedMsg
+		}
+	}
+
+	canvas, err := src.readCanvas()
+	if err != nil {
+		return updatePreviewMsg{err: err, source: src}
+	}
+
+	opts := src.options
+	opts.Ink = canvas.measure.Ink
+
+	return updatePreviewMsg{
+		pixels: canvas.pixels,
+		colors: convert.SampleColors(canvas.img, canvas.measure, opts),
+		img:    canvas.img,
+
+		source:   src,
+		cacheKey: newCacheKey,
+		measure:  canvas.measure,
+		paddingX: canvas.paddingX,
+		paddingY: canvas.paddingY,
+	}
+}
+
+// Keeps the measurement and the cache of a successful read.
+func (m *previewArtModel) takePreview(msg updatePreviewMsg) {
+	if msg.err != nil {
+		return
+	}
+
+	m.paddingX = msg.paddingX
+	m.paddingY = msg.paddingY
+	m.cellH = msg.measure.CellH
+	m.unpadded = msg.measure.IsUnpadded
+
+	m.measure = msg.measure
+	m.shadedDots, _ = inkDelta(nil, msg.pixels)
+
+	m.cacheKey = msg.cacheKey
+	m.cachedMsg = msg
+}
+
+type decodedCanvas struct {
+	pixels  [][]rune
+	img     image.Image
+	measure convert.CanvasMeasure
+
+	paddingX int
+	paddingY int
+}
+
+func readCanvasFile(fileName string, opts convert.ReadOptions) (decodedCanvas, error) {
+	file, err := os.Open(fileName)
 	if err != nil {
-		err := decodeError{FileDoesNotExistError}
-		return updatePreviewMsg{err, nil}
+		return decodedCanvas{}, decodeError{FileDoesNotExistError}
 	}
 
 	defer file.Close()
 
-	dotChars := strings.Count(model.fileName, ".")
-	if dotChars < 3 {
-		return updatePreviewMsg{InvalidFileNameError, nil}
+	if isBrailleTextFile(fileName) {
+		return decodeBrailleText(file, opts)
 	}
 
-	fileNameInfo := strings.Split(model.fileName, ".")
-	slices.Reverse(fileNameInfo)
+	return decodeCanvas(file, fileName, opts)
+}
+
+const brailleTextExtension = ".txt"
+
+func isBrailleTextFile(fileName string) bool {
+	return strings.EqualFold(filepath.Ext(fileName), brailleTextExtension)
+}
 
-	if imgExtension := fileNameInfo[0]; imgExtension != "png" {
-		return updatePreviewMsg{InvalidFileNameError, nil}
+// Braille text is drawn into a canvas image with the default padding, as if it was imported,
+// so it previews and exports like a benday file.
+func decodeBrailleText(r io.Reader, opts convert.ReadOptions) (decodedCanvas, error) {
+	pixels, _, err := importPixelData(r, forceImport)
+	if err != nil {
+		return decodedCanvas{}, decodeError{err}
 	}
 
-	if hasBy := fileNameInfo[1] == "by"; !hasBy {
-		return updatePreviewMsg{InvalidFileNameError, nil}
+	img := convert.NewPixelsImage(pixels, defaultPaddingX, defaultPaddingY, newCanvasCellH, convert.AlignStart, baseReadOptions.Ink)
+
+	measure, err := convert.MeasureCanvasImage(img, defaultPaddingX, defaultPaddingY, newCanvasCellH, convert.AlignStart, opts)
+	if err != nil {
+		return decodedCanvas{}, decodeError{err}
 	}
 
-	paddingSpec := fileNameInfo[2]
-	if strings.Count(paddingSpec, "x") != 1 {
-		return updatePreviewMsg{InvalidFileNameError, nil}
+	canvas := decodedCanvas{
+		pixels:   convert.SamplePixels(img, measure, opts),
+		img:      img,
+		measure:  measure,
+		paddingX: defaultPaddingX,
+		paddingY: defaultPaddingY,
 	}
 
-	paddingSpecSplit := strings.Split(paddingSpec, "x")
+	return canvas, nil
+}
 
-	if isValidPadding(paddingSpecSplit[0]) != nil || isValidPadding(paddingSpecSplit[1]) != nil {
-		err := fmt.Errorf("Padding is an invalid value: %w", NotAPositiveNumberError)
-		return updatePreviewMsg{err, nil}
+// The file name is only used to read the padding specification.
+func decodeCanvas(r io.Reader, fileName string, opts convert.ReadOptions) (decodedCanvas, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return decodedCanvas{}, decodeError{fmt.Errorf("Error reading the image: %w", err)}
 	}
 
-	paddingX, _ := strconv.Atoi(paddingSpecSplit[0])
-	paddingY, _ := strconv.Atoi(paddingSpecSplit[1])
+	paddingX, paddingY, err := canvasPadding(data, fileName)
+	if err != nil {
+		return decodedCanvas{}, err
+	}
 
-	model.paddingX = paddingX
-	model.paddingY = paddingY
+	return decodeCanvasData(data, paddingX, paddingY, opts)
+}
 
-	m, err := getCanvasMeasurement(model.fileName, paddingX, paddingY)
+// Decodes with the given padding, ignoring the padding chunk and file name.
+func decodeCanvasData(data []byte, paddingX int, paddingY int, opts convert.ReadOptions) (decodedCanvas, error) {
+	img, err := png.Decode(bytes.NewReader(data))
 	if err != nil {
-		return updatePreviewMsg{err, nil}
+		return decodedCanvas{}, decodeError{fmt.Errorf("Error reading the image: %w", err)}
 	}
 
-	model.unpadded = m.isUnpadded
+	opts.Ink = inkFromChunk(data, opts.Ink)
 
-	img, err := png.Decode(file)
+	m, err := convert.MeasureCanvasImage(img, paddingX, paddingY, cellHeightFromChunk(data), alignmentFromChunk(data), opts)
 	if err != nil {
-		return updatePreviewMsg{
-			decodeError{fmt.Errorf("Error reading the image: %w", err)}, nil,
-		}
+		return decodedCanvas{}, err
 	}
 
-	pixels := make([][]rune, m.charsY)
-	for y := range pixels {
-		pixels[y] = make([]rune, m.charsX)
+	m.Background = backgroundFromChunk(data)
+
+	canvas := decodedCanvas{
+		pixels:   convert.SamplePixels(img, m, opts),
+		img:      img,
+		measure:  m,
+		paddingX: paddingX,
+		paddingY: paddingY,
 	}
+	return canvas, nil
+}
 
-	bitRep := make([]rune, 0, 8)
-	for charY := range m.charsY {
-		for charX := range m.charsX {
-			for charYOff := BRAILLE_HEIGHT - 1; charYOff >= 0; charYOff -= 1 {
-				for charXOff := BRAILLE_WIDTH - 1; charXOff >= 0; charXOff -= 1 {
-					x := charX*m.brailleW + charXOff
-					y := charY*m.brailleH + charYOff
+// Matched from the end, so the prefix can hold any characters, dots included.
+var canvasFileNamePattern = regexp.MustCompile(`^(.+)\.(\d+)x(\d+)\.by\.png$`)
 
-					if shadeType(img.At(x, y)) == colorShaded {
-						bitRep = append(bitRep, '1')
-					} else {
-						bitRep = append(bitRep, '0')
-					}
-				}
-			}
+// Splits "<prefix>.<pX>x<pY>.by.png" into the prefix and the padding specification.
+func splitCanvasFileName(fileName string) (string, string, bool) {
+	match := canvasFileNamePattern.FindStringSubmatch(filepath.Base(fileName))
+	if match == nil {
+		return "", "", false
+	}
 
-			brailleIdx, _ := strconv.ParseUint(string(bitRep), 2, 8)
-			pixels[charY][charX] = brailleLookup[brailleIdx]
+	return match[1], match[2] + "x" + match[3], true
+}
 
-			bitRep = bitRep[:0]
-		}
+func paddingFromFileName(fileName string) (int, int, error) {
+	_, paddingSpec, ok := splitCanvasFileName(fileName)
+	if !ok {
+		return 0, 0, InvalidFileNameError
+	}
+
+	return parsePaddingSpec(paddingSpec)
+}
+
+// Parses padding in the "<pX>x<pY>" form used in benday file names.
+func parsePaddingSpec(paddingSpec string) (int, int, error) {
+	paddingSpecSplit := strings.Split(paddingSpec, "x")
+	if len(paddingSpecSplit) != 2 {
+		return 0, 0, fmt.Errorf("Padding must be in the form \"<pX>x<pY>\", but is instead \"%v\".", paddingSpec)
 	}
 
-	return updatePreviewMsg{nil, pixels}
+	if isValidPadding(paddingSpecSplit[0]) != nil || isValidPadding(paddingSpecSplit[1]) != nil {
+		return 0, 0, fmt.Errorf("Padding is an invalid value: %w", NotAPositiveNumberError)
+	}
+
+	paddingX, _ := strconv.Atoi(paddingSpecSplit[0])
+	paddingY, _ := strconv.Atoi(paddingSpecSplit[1])
+
+	return paddingX, paddingY, nil
 }
 
 func togglePaddingState(fileName string, paddingX int, paddingY int) error {
@@ -268,12 +643,17 @@ func togglePaddingState(fileName string, paddingX int, paddingY int) error {
 
 	type bDimension struct{ w, h int }
 
-	beforeMeasure := bDimension{m.brailleW, m.brailleH}
+	beforeMeasure := bDimension{m.BrailleW, m.BrailleH}
 	afterMeasure := beforeMeasure
 
-	if m.isUnpadded {
+	// The dots move between the aligned spot of a padded character and the top left.
+	beforeOffset := m.DotOffset()
+	afterOffset := image.Point{}
+
+	if m.IsUnpadded {
 		afterMeasure.w += paddingX
 		afterMeasure.h += paddingY
+		afterOffset = m.Align.Offset(paddingX, paddingY)
 	} else {
 		afterMeasure.w -= paddingX
 		afterMeasure.h -= paddingY
@@ -291,22 +671,22 @@ func togglePaddingState(fileName string, paddingX int, paddingY int) error {
 		return decodeError{err}
 	}
 
-	newImageMeasure := bDimension{m.charsX * afterMeasure.w, m.charsY * afterMeasure.h}
-	if !m.isUnpadded {
+	newImageMeasure := bDimension{m.CharsX * afterMeasure.w, m.CharsY * afterMeasure.h}
+	if !m.IsUnpadded {
 		newImageMeasure.w += 1
 		newImageMeasure.h += 1
 	}
 
 	newImage := draw.Image(image.NewNRGBA(image.Rect(0, 0, newImageMeasure.w, newImageMeasure.h)))
-	for charY := range m.charsY {
-		for charX := range m.charsX {
-			for brailleYOff := range BRAILLE_HEIGHT {
-				for brailleXOff := range BRAILLE_WIDTH {
-					beforeX := charX*beforeMeasure.w + brailleXOff
-					beforeY := charY*beforeMeasure.h + brailleYOff
+	for charY := range m.CharsY {
+		for charX := range m.CharsX {
+			for brailleYOff := range m.CellH {
+				for brailleXOff := range convert.BRAILLE_WIDTH {
+					beforeX := charX*beforeMeasure.w + beforeOffset.X + brailleXOff
+					beforeY := charY*beforeMeasure.h + beforeOffset.Y + brailleYOff
 
-					afterX := charX*afterMeasure.w + brailleXOff
-					afterY := charY*afterMeasure.h + brailleYOff
+					afterX := charX*afterMeasure.w + afterOffset.X + brailleXOff
+					afterY := charY*afterMeasure.h + afterOffset.Y + brailleYOff
 
 					pxBefore := oldImage.At(beforeX, beforeY)
 					newImage.Set(afterX, afterY, pxBefore)
@@ -315,8 +695,12 @@ func togglePaddingState(fileName string, paddingX int, paddingY int) error {
 		}
 	}
 
-	if m.isUnpadded {
-		newImage = drawPadding(newImage, paddingX, paddingY)
+	if m.IsUnpadded {
+		newImage = convert.DrawPadding(newImage, paddingX, paddingY, m.CellH, m.Align)
+	}
+
+	if err := backupCanvas(fileName); err != nil {
+		return err
 	}
 
 	wFile, err := os.Create(fileName)
@@ -324,51 +708,11 @@ func togglePaddingState(fileName string, paddingX int, paddingY int) error {
 		return decodeError{err}
 	}
 
-	encodeError := png.Encode(wFile, newImage)
+	encodeError := encodeCanvas(wFile, newImage, paddingX, paddingY, m.CellH, m.Background, m.Align, m.Ink)
 	return encodeError
 }
 
-type shadedType int
-
-const (
-	colorTransparent shadedType = iota
-	colorNonGrayscale
-	colorNonShaded
-	colorShaded
-)
-
-// This ignores sufficiently translucent, non-grayscale, and light colors.
-func shadeType(c color.Color) shadedType {
-	pxColor := color.NRGBAModel.Convert(c).(color.NRGBA)
-	r, g, b, a := uint32(pxColor.R), uint32(pxColor.G), uint32(pxColor.B), uint32(pxColor.A)
-
-	if 3*a < 0xff {
-		return colorTransparent
-	}
-
-	// Derivation of "deviation":
-	// deviation = (abs(r, g) + abs(g, b) + abs(r, b)) / 3
-	// deviation = (r-g + g-b + r-b) / 3       (without loss of generality: r >= g >= b)
-	// deviation = 2 * (r-b) / 3
-	// deviation = 2 * (maximum(r, g, b) - minimum(r, g, b)) / 3
-	// (then multiplied the divisor to the other side)
-
-	// Originally as:
-	// `if deviation := (abs(r - g) + abs(g - b) + abs(r - b)) / 3; deviation > 0xff/16 { ... }`
-	if deviation := 2 * (max(r, g, b) - min(r, g, b)); 16*deviation > 3*0xff {
-		return colorNonGrayscale
-	}
-
-	// 3 color channels * 2/3 brightness = 2 multiplier to alpha
-	sumOfColors := r + g + b
-	if sumOfColors < 2*a {
-		return colorShaded
-	} else {
-		return colorNonShaded
-	}
-}
-
-func cleanCanvas(fileName string, paddingX int, paddingY int, removeNonGrayscale bool) error {
+func cleanCanvas(fileName string, paddingX int, paddingY int, removeNonGrayscale bool, opts convert.ReadOptions) error {
 	fileStats, err := os.Stat(fileName)
 	if err != nil {
 		return decodeError{FileDoesNotExistError}
@@ -378,11 +722,24 @@ func cleanCanvas(fileName string, paddingX int, paddingY int, removeNonGrayscale
 		return silentError{err}
 	}
 
+	return forceCleanCanvas(fileName, paddingX, paddingY, removeNonGrayscale, opts)
+}
+
+// Same as cleanCanvas, without the guard against recently modified files.
+func forceCleanCanvas(fileName string, paddingX int, paddingY int, removeNonGrayscale bool, opts convert.ReadOptions) error {
+	// The rewritten dots and background would read the other way around.
+	if opts.Invert {
+		return InvertedReadingError
+	}
+
 	m, err := getCanvasMeasurement(fileName, paddingX, paddingY)
 	if err != nil {
 		return err
 	}
 
+	// Dots of the ink the file was written with stay shaded, even when it is not the --ink.
+	opts.Ink = m.Ink
+
 	file, err := os.Open(fileName)
 	if err != nil {
 		return decodeError{FileDoesNotExistError}
@@ -395,24 +752,25 @@ func cleanCanvas(fileName string, paddingX int, paddingY int, removeNonGrayscale
 		return decodeError{err}
 	}
 
-	newImage := draw.Image(image.NewNRGBA(image.Rect(0, 0, m.imageWidth, m.imageHeight)))
+	newImage := draw.Image(image.NewNRGBA(image.Rect(0, 0, m.ImageWidth, m.ImageHeight)))
 	draw.Draw(newImage, img.Bounds(), img, image.Point{}, draw.Src)
 
-	defaultCanvasImg := newCanvasImage(m.imageWidth, m.imageHeight, paddingX, paddingY, m.isUnpadded)
+	defaultCanvasImg := convert.NewCanvasImage(m.ImageWidth, m.ImageHeight, paddingX, paddingY, m.IsUnpadded, m.CellH, m.Background, m.Align)
 	maskForDefault := image.NewAlpha16(img.Bounds())
 
-	for bigOffsetX := 0; bigOffsetX < m.imageWidth; bigOffsetX += m.brailleW {
-		for bigOffsetY := 0; bigOffsetY < m.imageHeight; bigOffsetY += m.brailleH {
-			for charX := range BRAILLE_WIDTH {
-				for charY := range BRAILLE_HEIGHT {
-					x := bigOffsetX + charX
-					y := bigOffsetY + charY
+	dotOffset := m.DotOffset()
+
+	for bigOffsetX := 0; bigOffsetX < m.ImageWidth; bigOffsetX += m.BrailleW {
+		for bigOffsetY := 0; bigOffsetY < m.ImageHeight; bigOffsetY += m.BrailleH {
+			for charX := range convert.BRAILLE_WIDTH {
+				for charY := range m.CellH {
+					x := bigOffsetX + dotOffset.X + charX
+					y := bigOffsetY + dotOffset.Y + charY
 
-					shade := shadeType(newImage.At(x, y))
+					shade := convert.ShadeTypeAt(newImage.At(x, y), opts)
 
-					if shade == colorShaded {
-						colorBlack := color.NRGBA{0x33, 0x33, 0x33, 0xff}
-						newImage.Set(x, y, colorBlack)
+					if shade == convert.ColorShaded {
+						newImage.Set(x, y, m.Ink)
 
 						continue
 					}
@@ -422,7 +780,7 @@ func cleanCanvas(fileName string, paddingX int, paddingY int, removeNonGrayscale
 						continue
 					}
 
-					if shade != colorNonGrayscale {
+					if shade != convert.ColorNonGrayscale {
 						maskForDefault.Set(x, y, color.Opaque)
 					}
 				}
@@ -432,16 +790,20 @@ func cleanCanvas(fileName string, paddingX int, paddingY int, removeNonGrayscale
 
 	draw.DrawMask(newImage, img.Bounds(), defaultCanvasImg, image.Point{}, maskForDefault, image.Point{}, draw.Over)
 
-	if m.isUnpadded {
+	if m.IsUnpadded {
 		transparentImg := image.NewUniform(color.NRGBA{})
 
-		verticalRect := image.Rect(m.imageWidth-1, 0, m.imageWidth, m.imageHeight)
-		horizontalRect := image.Rect(0, m.imageHeight-1, m.imageWidth, m.imageHeight)
+		verticalRect := image.Rect(m.ImageWidth-1, 0, m.ImageWidth, m.ImageHeight)
+		horizontalRect := image.Rect(0, m.ImageHeight-1, m.ImageWidth, m.ImageHeight)
 
 		draw.Draw(newImage, verticalRect, transparentImg, image.Point{}, draw.Src)
 		draw.Draw(newImage, horizontalRect, transparentImg, image.Point{}, draw.Src)
 	} else {
-		newImage = drawPadding(newImage, paddingX, paddingY)
+		newImage = convert.DrawPadding(newImage, paddingX, paddingY, m.CellH, m.Align)
+	}
+
+	if err := backupCanvas(fileName); err != nil {
+		return err
 	}
 
 	file, err = os.Create(fileName)
@@ -449,77 +811,94 @@ func cleanCanvas(fileName string, paddingX int, paddingY int, removeNonGrayscale
 		return err
 	}
 
-	encodeError := png.Encode(file, newImage)
+	encodeError := encodeCanvas(file, newImage, paddingX, paddingY, m.CellH, m.Background, m.Align, m.Ink)
 	return encodeError
 }
 
-func getCanvasMeasurement(fileName string, paddingX int, paddingY int) (canvasMeasure, error) {
-	file, err := os.Open(fileName)
+func getCanvasMeasurement(fileName string, paddingX int, paddingY int) (convert.CanvasMeasure, error) {
+	data, err := os.ReadFile(fileName)
 	if err != nil {
-		return canvasMeasure{}, decodeError{FileDoesNotExistError}
+		return convert.CanvasMeasure{}, decodeError{FileDoesNotExistError}
 	}
 
-	config, err := png.DecodeConfig(file)
-	file.Close()
-
+	config, err := png.DecodeConfig(bytes.NewReader(data))
 	if err != nil {
-		return canvasMeasure{}, decodeError{err}
+		return convert.CanvasMeasure{}, decodeError{err}
 	}
 
-	imageTestWidth := config.Width
-	imageTestHeight := config.Height
-
-	brailleW := BRAILLE_WIDTH + paddingX
-	brailleH := BRAILLE_HEIGHT + paddingY
-
-	padded := imageTestWidth%brailleW == 0 && imageTestHeight%brailleH == 0
-	if !padded {
-		brailleW = BRAILLE_WIDTH
-		brailleH = BRAILLE_HEIGHT
-
-		imageTestWidth -= 1
-		imageTestHeight -= 1
-	}
+	cellH := cellHeightFromChunk(data)
+	align := alignmentFromChunk(data)
 
-	charsX := imageTestWidth / brailleW
-	charsY := imageTestHeight / brailleH
+	hasTransparentPadding := func() (bool, error) {
+		img, err := png.Decode(bytes.NewReader(data))
+		if err != nil {
+			return false, decodeError{err}
+		}
 
-	if charsX*brailleW != imageTestWidth {
-		err := InvalidImgDimensionE{config.Width, brailleW, true, !padded}
-		return canvasMeasure{}, err
+		return convert.IsPaddingTransparent(img, paddingX, paddingY, cellH, align, baseReadOptions), nil
 	}
 
-	if charsY*brailleH != imageTestHeight {
-		err := InvalidImgDimensionE{config.Height, brailleW, true, !padded}
-		return canvasMeasure{}, err
-	}
+	m, err := convert.MeasureCanvas(config.Width, config.Height, paddingX, paddingY, cellH, hasTransparentPadding)
+	m.Background = backgroundFromChunk(data)
+	m.Align = align
+	m.Ink = inkFromChunk(data, baseReadOptions.Ink)
 
-	measurements := canvasMeasure{
-		imageWidth:  config.Width,
-		imageHeight: config.Height,
-		isUnpadded:  !padded,
-		charsX:      charsX,
-		charsY:      charsY,
-		brailleW:    brailleW,
-		brailleH:    brailleH,
-	}
-	return measurements, nil
+	return m, err
 }
 
 func (m *previewArtModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.windowWidth, m.windowHeight = msg.Width, msg.Height
+		m.viewOffset = m.visibleBounds().Min
+
+		return m, nil
 	case tea.KeyMsg:
+		// Quitting or going back mid-write would leave a half written file behind.
+		if m.working != "" {
+			return m, nil
+		}
+
+		if m.confirmingDiscard {
+			return m.updateDiscard(msg)
+		}
+
 		switch msg.String() {
 		case "ctrl+c":
+			if m.hasPendingInput() || m.buffer != nil {
+				m.confirmingDiscard = true
+				m.discardQuits = true
+
+				return m, nil
+			}
+
 			return m, tea.Quit
 		case "esc":
+			if m.hasPendingInput() {
+				m.confirmingDiscard = true
+				m.discardQuits = false
+
+				return m, nil
+			}
+
 			if m.rOpts.resizing {
 				m.rOpts.resizing = false
 				return m, nil
 			}
 
+			if m.confirmingReset {
+				m.confirmingReset = false
+				return m, nil
+			}
+
+			if m.editing {
+				m.editing = false
+				return m, nil
+			}
+
 			if m.exportOpts.showConfirmPrompt {
 				m.exportOpts.showConfirmPrompt = false
+				m.exportOpts.overwriting = false
 				m.processError = nil
 
 				return m, nil
@@ -530,17 +909,50 @@ func (m *previewArtModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				return m, nil
 			}
 
-			if m._fromArgs {
-				return m, tea.Quit
+			if m.saveOpts.saving {
+				m.saveOpts.saving = false
+				m.saveOpts.err = nil
+
+				return m, nil
 			}
 
-			startModel := newBendayStartModel()
-			return startModel, startModel.Init()
-		}
-	}
+			if m.repadOpts.repadding {
+				m.repadOpts.repadding = false
+				m.repadOpts.err = nil
 
-	if len(m.writeSignal) != 0 {
-		if _, isUpdateMsg := msg.(updatePreviewMsg); !isUpdateMsg {
+				return m, nil
+			}
+
+			if m.buffer != nil {
+				m.confirmingDiscard = true
+				m.discardQuits = false
+
+				return m, nil
+			}
+
+			if m._fromArgs && !m._escToMenu {
+				return m, tea.Quit
+			}
+
+			if m._fromProject != "" {
+				projectModel := newProjectModel(m._fromProject)
+				return projectModel, projectModel.Init()
+			}
+
+			if m.archivePath != "" {
+				archiveModel := newArchiveModel(m.archivePath)
+				return archiveModel, archiveModel.Init()
+			}
+
+			startModel := newBendayStartModel()
+			return startModel, startModel.Init()
+		}
+	}
+
+	if len(m.writeSignal) != 0 {
+		switch msg.(type) {
+		case updatePreviewMsg, canvasOpDoneMsg, watchTickMsg:
+		default:
 			return m, nil
 		}
 	}
@@ -550,6 +962,7 @@ func (m *previewArtModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			if _, ok := msg.(tea.KeyMsg); ok {
 				if opts.showConfirmPrompt {
 					opts.showConfirmPrompt = false
+					opts.overwriting = false
 					m.processError = nil
 
 					focusMsg := opts.input.Focus()
@@ -557,7 +970,7 @@ func (m *previewArtModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				}
 			}
 
-			if _, isUpdateMsg := msg.(updatePreviewMsg); !isUpdateMsg {
+			if !isWatchMsg(msg) {
 				return m, nil
 			}
 		}
@@ -568,6 +981,36 @@ func (m *previewArtModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					switch msg.String() {
 					case "enter":
 						opts.showConfirmPrompt = true
+						return m, nil
+					case "tab":
+						opts.contentMode = (opts.contentMode + 1) % 3
+						return m, nil
+					case "shift+tab":
+						opts.format = (opts.format + 1) % 3
+						return m, nil
+					case "ctrl+t":
+						opts.keepGrid = !opts.keepGrid
+						return m, nil
+					case "ctrl+l":
+						opts.htmlLines = !opts.htmlLines
+						return m, nil
+					case "ctrl+b":
+						opts.spaceBlanks = !opts.spaceBlanks
+						return m, nil
+					case "ctrl+r":
+						opts.aspect = (opts.aspect + 1) % len(exportAspects)
+						return m, nil
+					case "up", "down":
+						if opts.format == exportBrailleText {
+							return m, nil
+						}
+
+						if msg.String() == "up" {
+							opts.scale = min(opts.scale+1, maxExportScale)
+						} else {
+							opts.scale = max(opts.scale-1, 1)
+						}
+
 						return m, nil
 					}
 				}
@@ -578,7 +1021,87 @@ func (m *previewArtModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				case tea.KeyMsg:
 					switch msg.String() {
 					case "y", "enter":
-						if err := exportBraille(opts.input.Value(), m.pixels); err != nil {
+						if opts.overwriting && msg.String() != "y" {
+							return m, nil
+						}
+
+						if _, err := os.Stat(opts.input.Value()); err == nil && !opts.overwriting {
+							opts.overwriting = true
+							return m, nil
+						}
+
+						pixels := m.pixels
+						colors := [][]color.Color(nil)
+						header := ""
+
+						// Ansi files exist to carry the colors, so they are always colored.
+						if m.colored || isANSIFileName(opts.input.Value()) {
+							colors = m.colors
+						}
+
+						exportBounds := image.Rectangle{}
+						if len(m.pixels) != 0 {
+							exportBounds = image.Rect(0, 0, len(m.pixels[0]), len(m.pixels))
+						}
+
+						selection, hasSelection := m.selection()
+						if hasSelection {
+							exportBounds = selection
+						}
+
+						if opts.contentMode != exportWholeCanvas {
+							bounds, hasContent := contentBounds(cropPixels(m.pixels, exportBounds))
+							if !hasContent {
+								m.processError = fmt.Errorf("Nothing to export, the canvas is blank.")
+								if hasSelection {
+									m.processError = fmt.Errorf("Nothing to export, the selection is blank.")
+								}
+
+								return m, nil
+							}
+
+							exportBounds = bounds.Add(exportBounds.Min)
+
+							if opts.contentMode == exportContentWithHeader {
+								header = fmt.Sprintf(
+									"benday offset=%v,%v size=%vx%v canvas=%vx%v",
+									exportBounds.Min.X, exportBounds.Min.Y,
+									exportBounds.Dx(), exportBounds.Dy(),
+									len(m.pixels[0]), len(m.pixels),
+								)
+							}
+						}
+
+						if hasSelection || opts.contentMode != exportWholeCanvas {
+							pixels = cropPixels(m.pixels, exportBounds)
+
+							if colors != nil {
+								colors = cropPixels(colors, exportBounds)
+							}
+						}
+
+						if opts.format != exportBrailleText {
+							canvas, err := m.previewSource().readCanvas()
+							if err == nil {
+								round := opts.format == exportRoundDots
+								err = exportScaledPNG(opts.input.Value(), canvas, opts.scale, round, exportAspects[opts.aspect], exportBounds, opts.overwriting)
+							}
+
+							if err != nil {
+								m.processError = err
+								return m, nil
+							}
+						} else if isHTMLFileName(opts.input.Value()) {
+							if err := exportHTML(opts.input.Value(), pixels, colors, header, !opts.keepGrid, opts.htmlLines, opts.overwriting); err != nil {
+								m.processError = err
+								return m, nil
+							}
+						} else if isANSIFileName(opts.input.Value()) {
+							if err := exportANSI(opts.input.Value(), pixels, colors, header, !opts.keepGrid, opts.overwriting); err != nil {
+								m.processError = err
+								return m, nil
+							}
+						} else if err := exportBraille(opts.input.Value(), pixels, header, !opts.keepGrid, opts.spaceBlanks, opts.overwriting); err != nil {
 							m.processError = err
 							return m, nil
 						}
@@ -588,10 +1111,12 @@ func (m *previewArtModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 						opts.exporting = false
 						opts.showConfirmPrompt = false
+						opts.overwriting = false
 
 						return m, nil
-					case "b":
+					case "b", "n":
 						opts.showConfirmPrompt = false
+						opts.overwriting = false
 
 						focusCmd := opts.input.Focus()
 						return m, focusCmd
@@ -600,7 +1125,33 @@ func (m *previewArtModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 		}
 
-		if _, isUpdateMsg := msg.(updatePreviewMsg); !isUpdateMsg {
+		if !isWatchMsg(msg) {
+			var cmd tea.Cmd
+			opts.input, cmd = opts.input.Update(msg)
+
+			return m, cmd
+		}
+	}
+
+	if opts := &m.saveOpts; opts.saving {
+		if keyMsg, isKeyMsg := msg.(tea.KeyMsg); isKeyMsg {
+			return m.updateSaveAs(keyMsg)
+		}
+
+		if !isWatchMsg(msg) {
+			var cmd tea.Cmd
+			opts.input, cmd = opts.input.Update(msg)
+
+			return m, cmd
+		}
+	}
+
+	if opts := &m.repadOpts; opts.repadding {
+		if keyMsg, isKeyMsg := msg.(tea.KeyMsg); isKeyMsg {
+			return m.updateRepad(keyMsg)
+		}
+
+		if !isWatchMsg(msg) {
 			var cmd tea.Cmd
 			opts.input, cmd = opts.input.Update(msg)
 
@@ -608,6 +1159,145 @@ func (m *previewArtModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 	}
 
+	if msg, isKeyMsg := msg.(tea.KeyMsg); isKeyMsg && msg.String() == "?" && !m.rOpts.resizing && !m.confirmingReset {
+		return newHelpOverlay(m), nil
+	}
+
+	// Undo and saving are left to the regular preview keys.
+	if msg, isKeyMsg := msg.(tea.KeyMsg); isKeyMsg && m.editing && msg.String() != "u" && msg.String() != "ctrl+s" {
+		if len(m.pixels) == 0 {
+			return m, nil
+		}
+
+		charsX, charsY := len(m.pixels[0]), len(m.pixels)
+
+		switch key := msg.String(); key {
+		case " ":
+			m.editing = false
+		case "up", "down", "left", "right", "shift+up", "shift+down", "shift+left", "shift+right":
+			step := map[string]image.Point{
+				"up":    {0, -1},
+				"down":  {0, 1},
+				"left":  {-1, 0},
+				"right": {1, 0},
+			}[strings.TrimPrefix(key, "shift+")]
+
+			extending := strings.HasPrefix(key, "shift+")
+			if extending && !m.selecting {
+				m.selectionAnchor = m.cursor
+			}
+
+			m.selecting = extending
+
+			m.cursor = m.cursor.Add(step)
+			m.cursor.X = min(max(m.cursor.X, 0), charsX-1)
+			m.cursor.Y = min(max(m.cursor.Y, 0), charsY-1)
+
+			m.scrollToCursor()
+		case "1", "2", "3", "4", "5", "6", "7", "8":
+			dotNumber, _ := strconv.Atoi(key)
+
+			if bufferEdits {
+				m.processError = m.editBuffered(func(b *editBuffer) bool {
+					return toggleDotImage(b.measure, b.img, b.paddingX, b.paddingY, m.cursor, dotNumber, m.symmetry, m.readOptions())
+				})
+
+				if m.processError != nil {
+					return panicMsgModel(m.processError.Error()), nil
+				}
+
+				return m, nil
+			}
+
+			snapshot := readSnapshot(m.fileName)
+
+			m.writeSignal <- struct{}{}
+			m.processError = toggleDot(m.fileName, m.paddingX, m.paddingY, m.cursor, dotNumber, m.symmetry, m.readOptions())
+			<-m.writeSignal
+
+			if m.processError != nil {
+				return panicMsgModel(m.processError.Error()), nil
+			}
+
+			m.pushUndo(snapshot)
+			m.loadPixels()
+		case "m":
+			m.symmetry = (m.symmetry + 1) % (symmetryBoth + 1)
+
+			m.notifTime = time.Now()
+			m.notifMessage = fmt.Sprintf("mirroring edits: %v", m.symmetry)
+		case "f":
+			cells := floodFillCells(m.pixels, m.cursor, m.cellH)
+			if len(cells) == 0 {
+				m.notifTime = time.Now()
+				m.notifMessage = "can only fill from a blank or fully shaded character"
+
+				return m, nil
+			}
+
+			shade := m.pixels[m.cursor.Y][m.cursor.X] == convert.BrailleLookup[0]
+
+			if bufferEdits {
+				m.processError = m.editBuffered(func(b *editBuffer) bool {
+					fillCellsImage(b.measure, b.img, b.paddingX, b.paddingY, cells, shade, m.symmetry)
+					return true
+				})
+			} else {
+				snapshot := readSnapshot(m.fileName)
+
+				m.writeSignal <- struct{}{}
+				m.processError = fillCells(m.fileName, m.paddingX, m.paddingY, cells, shade, m.symmetry)
+				<-m.writeSignal
+
+				if m.processError == nil {
+					m.pushUndo(snapshot)
+					m.loadPixels()
+				}
+			}
+
+			if m.processError != nil {
+				return panicMsgModel(m.processError.Error()), nil
+			}
+
+			m.notifTime = time.Now()
+			m.notifMessage = fmt.Sprintf("filled %v characters!", len(cells))
+		}
+
+		return m, nil
+	}
+
+	if msg, isKeyMsg := msg.(tea.KeyMsg); isKeyMsg && m.confirmingReset {
+		m.confirmingReset = false
+
+		switch msg.String() {
+		case "y", "enter":
+			pixelsBefore := readCanvasPixels(m.fileName, m.readOptions())
+			snapshot := readSnapshot(m.fileName)
+
+			m.writeSignal <- struct{}{}
+			m.processError = resetCanvas(m.fileName, m.paddingX, m.paddingY)
+			<-m.writeSignal
+
+			if m.processError != nil {
+				if _, isSilent := m.processError.(silentError); isSilent {
+					m.notifyTooRecent()
+					m.processError = nil
+					return m, nil
+				}
+
+				return panicMsgModel(m.processError.Error()), nil
+			}
+
+			m.pushUndo(snapshot)
+			m.loadPixels()
+
+			m.notifTime = time.Now()
+			m.notifMessage = "finished resetting the canvas!" + m.inkDeltaText(pixelsBefore)
+		}
+
+		return m, nil
+	}
+
 	if opts := &m.rOpts; opts.resizing {
 		toResizeIdx := 0
 		if opts.toResizeHeight {
@@ -628,10 +1318,37 @@ func (m *previewArtModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			switch msg.String() {
 			case "+", ">", ".", "up":
 				opts.inputs[toResizeIdx] += 1
+				opts.typedTarget = ""
 			case "-", "<", ",", "down":
 				opts.inputs[toResizeIdx] -= 1
+				opts.typedTarget = ""
 			case "tab", "shift+tab", "left", "right", "ctrl+n", "ctrl+p":
 				opts.toResizeHeight = !opts.toResizeHeight
+				opts.typedTarget = ""
+
+			case "a", "A":
+				anchorIdx := opts.anchor.Y*3 + opts.anchor.X
+				if msg.String() == "a" {
+					anchorIdx = (anchorIdx + 1) % 9
+				} else {
+					anchorIdx = (anchorIdx + 8) % 9
+				}
+
+				opts.anchor = image.Pt(anchorIdx%3, anchorIdx/3)
+
+			case "0", "1", "2", "3", "4", "5", "6", "7", "8", "9", "backspace":
+				if msg.String() == "backspace" {
+					opts.typedTarget = opts.typedTarget[:max(len(opts.typedTarget)-1, 0)]
+				} else if len(opts.typedTarget) < 4 {
+					opts.typedTarget += msg.String()
+				}
+
+				if isWholeNumber(opts.typedTarget) == nil {
+					target, _ := strconv.Atoi(opts.typedTarget)
+					currentChars := []int{measure.CharsX, measure.CharsY}[toResizeIdx]
+
+					opts.inputs[toResizeIdx] = target - currentChars
+				}
 
 			case "c":
 				opts.resizing = false
@@ -640,113 +1357,284 @@ func (m *previewArtModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			case "enter":
 				resizeX := opts.inputs[0]
 				resizeY := opts.inputs[1]
+				anchor := opts.anchor
 
-				m.writeSignal <- struct{}{}
-				m.processError = resizeCanvas(m.fileName, m.paddingX, m.paddingY, resizeX, resizeY)
-				<-m.writeSignal
-
-				if m.processError != nil {
-					if _, isSilent := m.processError.(silentError); isSilent {
-						m.processError = nil
-						return m, nil
-					}
-
-					return panicMsgModel(m.processError.Error()), nil
+				opts.resizing = false
+				if resizeX == 0 && resizeY == 0 {
+					return m, nil
 				}
 
-				if resizeX != 0 || resizeY != 0 {
+				// The added characters are blank background, which reads as shaded.
+				if m.inverted && (resizeX > 0 || resizeY > 0) {
 					m.notifTime = time.Now()
-					m.notifMessage = "finished resizing the canvas!"
+					m.notifMessage = strings.ToLower(InvertedReadingError.Error())
+
+					return m, nil
 				}
 
-				opts.resizing = false
-				return m, nil
+				fileName, paddingX, paddingY := m.fileName, m.paddingX, m.paddingY
+				resizeCmd := m.runCanvasOp("resizing the canvas", "finished resizing the canvas!", func() error {
+					return resizeCanvas(fileName, paddingX, paddingY, resizeX, resizeY, anchor)
+				})
+
+				return m, resizeCmd
 			}
 		}
 
-		if resizeWidth := opts.inputs[0]; resizeWidth+measure.charsX <= 0 {
-			opts.inputs[0] = -(measure.charsX - 1)
+		if resizeWidth := opts.inputs[0]; resizeWidth+measure.CharsX <= 0 {
+			opts.inputs[0] = -(measure.CharsX - 1)
 		}
 
-		if resizeHeight := opts.inputs[1]; resizeHeight+measure.charsY <= 0 {
-			opts.inputs[1] = -(measure.charsY - 1)
+		if resizeHeight := opts.inputs[1]; resizeHeight+measure.CharsY <= 0 {
+			opts.inputs[1] = -(measure.CharsY - 1)
 		}
 	}
 
 	switch msg := msg.(type) {
 	case updatePreviewMsg:
-		m.updateViewError = msg.err
+		// Read before the operation started writing, or even halfway through it. Reads of
+		// another file or with other options, and reads under buffered edits, are stale.
+		if m.working != "" || m.buffer != nil || msg.source != m.previewSource() {
+			return m.Tick()
+		}
+
+		// Only a file that never read is fatal. Later reads can catch it halfway through being saved.
+		if _, isDecodeError := msg.err.(decodeError); isDecodeError {
+			if len(m.pixels) != 0 {
+				m.unreadableError = msg.err
+				return m.Tick()
+			}
 
-		if _, shouldPanic := msg.err.(decodeError); shouldPanic {
 			panicMsg := panicMsgModel(
 				fmt.Sprintf("Filename: %v\n%v", m.fileName, msg.err),
 			)
 			return panicMsg, tea.Quit
 		}
 
+		m.updateViewError = msg.err
+		m.unreadableError = nil
+		m.takePreview(msg)
+
 		if msg.err == nil {
 			m.pixels = msg.pixels
+			m.colors = msg.colors
+			m.recordThumbnail()
+
+			if m.showSource && len(msg.pixels) != 0 {
+				m.sourceImage = encodeTerminalImage(msg.img, m.graphics, len(msg.pixels[0]), len(msg.pixels))
+			}
 		}
 
 		return m.Tick()
 
+	case watchTickMsg:
+		if m.watchPaused || m.buffer != nil {
+			return m.Tick()
+		}
+
+		m.watchTicker = !m.watchTicker
+
+		// The file is not read while a canvas operation is writing it.
+		if len(m.writeSignal) != 0 {
+			return m.Tick()
+		}
+
+		return m, m.readPreviewCmd()
+
+	case canvasOpDoneMsg:
+		<-m.writeSignal
+		m.working = ""
+
+		if msg.err != nil {
+			if _, isSilent := msg.err.(silentError); isSilent {
+				m.notifyTooRecent()
+				return m, nil
+			}
+
+			m.processError = msg.err
+			return panicMsgModel(msg.err.Error()), nil
+		}
+
+		m.pushUndo(msg.snapshot)
+		m.takePreview(msg.preview)
+		m.showPixels(msg.preview)
+
+		m.notifTime = time.Now()
+		m.notifMessage = msg.doneMessage + m.inkDeltaText(msg.pixelsBefore)
+
+		return m, nil
+
 	case tea.KeyMsg:
 		if m.rOpts.resizing {
 			return m, nil
 		}
 
-		if m.exportOpts.exporting {
+		if m.exportOpts.exporting || m.saveOpts.saving || m.repadOpts.repadding {
 			return m, nil
 		}
 
+		if m.archivePath != "" {
+			switch msg.String() {
+			case "r", "R", "c", "C", "t", "P", "u", "h", "J", "V", "{", "}", "x", "i", " ", "w", "D":
+				m.notifTime = time.Now()
+				m.notifMessage = strings.ToLower(ReadOnlyArchiveError.Error())
+
+				return m, nil
+			}
+		}
+
+		// Saving as (w) and duplicating (D) write the text out as a benday file, which can then be edited.
+		if isBrailleTextFile(m.fileName) {
+			switch msg.String() {
+			case "r", "R", "c", "C", "t", "P", "u", "h", "J", "V", "{", "}", "x", "i", " ":
+				m.notifTime = time.Now()
+				m.notifMessage = strings.ToLower(ReadOnlyTextCanvasError.Error())
+
+				return m, nil
+			}
+		}
+
+		// Everything else works on the file, which does not have the buffered edits yet.
+		if m.buffer != nil {
+			switch msg.String() {
+			case "r", "R", "c", "C", "t", "P", "{", "}", "x", "e", "w", "D", "tab", "ctrl+n", "ctrl+p":
+				m.notifTime = time.Now()
+				m.notifMessage = strings.ToLower(UnsavedEditsError.Error())
+
+				return m, nil
+			}
+		}
+
 		switch msg.String() {
+		case "ctrl+s":
+			m.notifTime = time.Now()
+
+			if m.buffer == nil {
+				m.notifMessage = "no unsaved edits"
+				if !bufferEdits {
+					m.notifMessage = "edits are already saved, run with --buffer-edits to save them with ctrl+s"
+				}
+
+				return m, nil
+			}
+
+			edits := m.buffer.edits
+			if err := m.saveBuffer(); err != nil {
+				m.notifMessage = fmt.Sprintf("cannot save: %v", err)
+				return m, nil
+			}
+
+			m.notifMessage = fmt.Sprintf("saved %v edits!", edits)
+			return m, nil
 		case "r":
 			m.rOpts = resizeOptionStore{resizing: true}
 			return m, nil
 		case "e":
 			m.exportOpts.exporting = true
+			m.exportOpts.spaceBlanks = m.spaceBlanks
 			m.exportOpts.input.SetValue("")
 
 			focusCmd := m.exportOpts.input.Focus()
 			return m, focusCmd
+		case "w":
+			if m.updateViewError != nil {
+				return m, nil
+			}
+
+			m.saveOpts = saveAsOptionStore{saving: true, input: m.saveOpts.input}
+			m.saveOpts.input.SetValue("")
+
+			focusCmd := m.saveOpts.input.Focus()
+			return m, focusCmd
+		case "D":
+			if m.updateViewError != nil {
+				return m, nil
+			}
+
+			suggestion := m.duplicatePrefix()
+
+			m.saveOpts = saveAsOptionStore{saving: true, duplicating: true, suggestion: suggestion, input: m.saveOpts.input}
+			m.saveOpts.input.SetValue(suggestion)
+			m.saveOpts.input.CursorEnd()
+
+			focusCmd := m.saveOpts.input.Focus()
+			return m, focusCmd
 		case "c", "C":
 			if m.processError != nil {
 				return m, nil
 			}
 
-			removeNonGrayscaleColors := msg.String() == "C"
+			if m.inverted {
+				m.notifTime = time.Now()
+				m.notifMessage = strings.ToLower(InvertedReadingError.Error())
 
-			m.writeSignal <- struct{}{}
-			m.processError = cleanCanvas(m.fileName, m.paddingX, m.paddingY, removeNonGrayscaleColors)
-			<-m.writeSignal
+				return m, nil
+			}
 
-			if m.processError != nil {
-				if _, isSilent := m.processError.(silentError); isSilent {
-					m.processError = nil
-					return m, nil
-				}
+			removeNonGrayscaleColors := msg.String() == "C"
 
-				return panicMsgModel(m.processError.Error()), nil
+			doneMessage := "finished cleaning the canvas!"
+			if removeNonGrayscaleColors {
+				doneMessage = "finished CLEANING the canvas!"
+			}
+
+			fileName, paddingX, paddingY, opts := m.fileName, m.paddingX, m.paddingY, m.readOptions()
+			cleanCmd := m.runCanvasOp("cleaning the canvas", doneMessage, func() error {
+				return cleanCanvas(fileName, paddingX, paddingY, removeNonGrayscaleColors, opts)
+			})
+
+			return m, cleanCmd
+		case "[", "]":
+			if msg.String() == "[" {
+				m.threshold = max(m.threshold-1, convert.MinShadeThreshold)
+			} else {
+				m.threshold = min(m.threshold+1, convert.MaxShadeThreshold)
 			}
 
+			m.loadPixels()
+			return m, nil
+		case "I":
+			m.inverted = !m.inverted
+			m.loadPixels()
+
 			m.notifTime = time.Now()
-			m.notifMessage = "finished cleaning the canvas!"
-			if removeNonGrayscaleColors {
-				m.notifMessage = "finished CLEANING the canvas!"
+			m.notifMessage = "reading dark dots on a light background"
+			if m.inverted {
+				m.notifMessage = "reading light dots on a dark background"
 			}
 
 			return m, nil
-		case "t":
+		case "h", "J", "V":
 			if m.processError != nil {
 				return m, nil
 			}
 
-			m.writeSignal <- struct{}{}
-			m.processError = togglePaddingState(m.fileName, m.paddingX, m.paddingY)
-			<-m.writeSignal
+			direction := flipVertical
+			if msg.String() == "h" {
+				direction = flipHorizontal
+			}
+
+			if bufferEdits {
+				m.processError = m.editBuffered(func(b *editBuffer) bool {
+					flipCanvasImage(b.measure, b.img, b.paddingX, b.paddingY, direction)
+					return true
+				})
+			} else {
+				snapshot := readSnapshot(m.fileName)
+
+				m.writeSignal <- struct{}{}
+				m.processError = flipCanvas(m.fileName, m.paddingX, m.paddingY, direction)
+				<-m.writeSignal
+
+				if m.processError == nil {
+					m.pushUndo(snapshot)
+					m.loadPixels()
+				}
+			}
 
 			if m.processError != nil {
 				if _, isSilent := m.processError.(silentError); isSilent {
+					m.notifyTooRecent()
 					m.processError = nil
 					return m, nil
 				}
@@ -755,218 +1643,1386 @@ func (m *previewArtModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 
 			m.notifTime = time.Now()
-			m.notifMessage = "finished toggling the padding!"
+			m.notifMessage = "flipped the canvas horizontally!"
+			if direction == flipVertical {
+				m.notifMessage = "flipped the canvas vertically!"
+			}
 
 			return m, nil
+		case "{", "}":
+			if m.processError != nil {
+				return m, nil
+			}
+
+			clockwise := msg.String() == "}"
+			snapshot := readSnapshot(m.fileName)
+
+			m.writeSignal <- struct{}{}
+			newFileName, err := rotateCanvas(m.fileName, m.paddingX, m.paddingY, clockwise)
+			<-m.writeSignal
+
+			if errors.Is(err, RotatedFileExistsError) {
+				m.notifTime = time.Now()
+				m.notifMessage = fmt.Sprintf("cannot rotate, %v already exists", newFileName)
+
+				return m, nil
+			}
+
+			if err != nil {
+				if _, isSilent := err.(silentError); isSilent {
+					m.notifyTooRecent()
+					return m, nil
+				}
+
+				return panicMsgModel(err.Error()), nil
+			}
+
+			if newFileName == m.fileName {
+				m.pushUndo(snapshot)
+			} else {
+				for i, argFile := range m._argFiles {
+					if argFile == m.fileName {
+						m._argFiles[i] = newFileName
+					}
+				}
+
+				m.fileName = newFileName
+				lastPreviewedFile = newFileName
+			}
+
+			m.sourceImage = ""
+			m.loadPixels()
+
+			m.notifTime = time.Now()
+			m.notifMessage = "rotated the canvas right!"
+			if !clockwise {
+				m.notifMessage = "rotated the canvas left!"
+			}
+
+			return m, nil
+		case "x":
+			if m.processError != nil {
+				return m, nil
+			}
+
+			bounds, hasContent := contentBounds(m.pixels)
+			if !hasContent {
+				m.notifTime = time.Now()
+				m.notifMessage = "nothing to crop"
+
+				return m, nil
+			}
+
+			if bounds == image.Rect(0, 0, len(m.pixels[0]), len(m.pixels)) {
+				m.notifTime = time.Now()
+				m.notifMessage = "canvas is already cropped to its content"
+
+				return m, nil
+			}
+
+			pixelsBefore := m.pixels
+			snapshot := readSnapshot(m.fileName)
+
+			m.writeSignal <- struct{}{}
+			m.processError = cropCanvas(m.fileName, m.paddingX, m.paddingY, bounds)
+			<-m.writeSignal
+
+			if m.processError != nil {
+				if _, isSilent := m.processError.(silentError); isSilent {
+					m.notifyTooRecent()
+					m.processError = nil
+					return m, nil
+				}
+
+				return panicMsgModel(m.processError.Error()), nil
+			}
+
+			m.pushUndo(snapshot)
+			m.loadPixels()
+
+			m.notifTime = time.Now()
+			m.notifMessage = fmt.Sprintf(
+				"cropped the canvas from %vx%v to %vx%v!",
+				len(pixelsBefore[0]), len(pixelsBefore), bounds.Dx(), bounds.Dy(),
+			)
+
+			return m, nil
+		case "i":
+			if m.processError != nil {
+				return m, nil
+			}
+
+			if m.inverted {
+				m.notifTime = time.Now()
+				m.notifMessage = strings.ToLower(InvertedReadingError.Error())
+
+				return m, nil
+			}
+
+			pixelsBefore := m.pixels
+
+			if bufferEdits {
+				m.processError = m.editBuffered(func(b *editBuffer) bool {
+					invertCanvasImage(b.measure, b.img, b.paddingX, b.paddingY, m.readOptions())
+					return true
+				})
+			} else {
+				pixelsBefore = readCanvasPixels(m.fileName, m.readOptions())
+				snapshot := readSnapshot(m.fileName)
+
+				m.writeSignal <- struct{}{}
+				m.processError = invertCanvas(m.fileName, m.paddingX, m.paddingY, m.readOptions())
+				<-m.writeSignal
+
+				if m.processError == nil {
+					m.pushUndo(snapshot)
+					m.loadPixels()
+				}
+			}
+
+			if m.processError != nil {
+				if _, isSilent := m.processError.(silentError); isSilent {
+					m.notifyTooRecent()
+					m.processError = nil
+					return m, nil
+				}
+
+				return panicMsgModel(m.processError.Error()), nil
+			}
+
+			m.notifTime = time.Now()
+			m.notifMessage = "inverted the canvas!" + m.inkDeltaText(pixelsBefore)
+
+			return m, nil
+		case "u":
+			if m.processError != nil {
+				return m, nil
+			}
+
+			m.notifTime = time.Now()
+
+			if len(m.undoHistory) == 0 {
+				m.notifMessage = "nothing to undo"
+				return m, nil
+			}
+
+			snapshot := m.undoHistory[len(m.undoHistory)-1]
+
+			var err error
+
+			switch b := snapshot.buffer; {
+			case b != nil && b.fileName == m.fileName:
+				m.buffer = b
+
+				// Back to how the file is, with nothing left to save.
+				if b.edits == 0 {
+					m.buffer = nil
+				}
+			case b != nil:
+				// Buffered edits of another file were saved before switching away from it.
+				m.writeSignal <- struct{}{}
+				err = writeCanvasImage(b.fileName, b.img, b.paddingX, b.paddingY, b.measure.CellH, b.measure.Background, b.measure.Align, b.measure.Ink)
+				<-m.writeSignal
+			default:
+				m.writeSignal <- struct{}{}
+				err = os.WriteFile(snapshot.fileName, snapshot.data, 0644)
+				<-m.writeSignal
+
+				// Changes from before the buffered edits take them back too.
+				if err == nil && snapshot.fileName == m.fileName {
+					m.buffer = nil
+				}
+			}
+
+			if err != nil {
+				m.notifMessage = fmt.Sprintf("cannot undo: %v", err)
+				return m, nil
+			}
+
+			m.undoHistory = m.undoHistory[:len(m.undoHistory)-1]
+			m.loadPixels()
+
+			m.notifMessage = "undid last change"
+			if snapshot.fileName != m.fileName {
+				m.notifMessage = fmt.Sprintf("undid last change to %v", snapshot.fileName)
+			}
+
+			return m, nil
+		case " ":
+			if len(m.pixels) == 0 || m.processError != nil {
+				return m, nil
+			}
+
+			if m.inverted {
+				m.notifTime = time.Now()
+				m.notifMessage = strings.ToLower(InvertedReadingError.Error())
+
+				return m, nil
+			}
+
+			m.editing = true
+			m.zoom = 1
+
+			m.cursor.X = min(m.cursor.X, len(m.pixels[0])-1)
+			m.cursor.Y = min(m.cursor.Y, len(m.pixels)-1)
+			m.scrollToCursor()
+
+			return m, nil
+		case "+", "=", "-":
+			if len(m.pixels) == 0 {
+				return m, nil
+			}
+
+			if msg.String() == "-" {
+				pixels, _ := m.displayedPixels()
+				if m.visibleBounds() == image.Rect(0, 0, len(pixels[0]), len(pixels)) {
+					m.notifTime = time.Now()
+					m.notifMessage = "whole canvas already fits"
+
+					return m, nil
+				}
+
+				m.zoom = max(m.zoom, 1) + 1
+			} else {
+				m.zoom = max(m.zoom-1, 1)
+			}
+
+			m.viewOffset = m.visibleBounds().Min
+			return m, nil
+		case "up", "down", "left", "right":
+			step := map[string]image.Point{
+				"up":    {0, -1},
+				"down":  {0, 1},
+				"left":  {-1, 0},
+				"right": {1, 0},
+			}[msg.String()]
+
+			m.viewOffset = m.visibleBounds().Min.Add(step)
+			m.viewOffset = m.visibleBounds().Min
+
+			return m, nil
+		case "p":
+			m.watchPaused = !m.watchPaused
+
+			if !m.watchPaused {
+				m.loadPixels()
+			}
+
+			return m, nil
+		case "y":
+			m.notifTime = time.Now()
+
+			if len(m.pixels) == 0 {
+				m.notifMessage = "nothing to copy: empty canvas"
+				return m, nil
+			}
+
+			if clipboard.Unsupported {
+				m.notifMessage = "no clipboard available on this system"
+				return m, nil
+			}
+
+			pixels := m.pixels
+			if m.spaceBlanks {
+				pixels = blanksAsSpaces(pixels)
+			}
+
+			if err := clipboard.WriteAll(pixelsToText(pixels)); err != nil {
+				m.notifMessage = fmt.Sprintf("cannot copy to clipboard: %v", err)
+				return m, nil
+			}
+
+			m.notifMessage = "copied to clipboard!"
+			return m, nil
+		case "g":
+			m.showGuides = !m.showGuides
+			m.viewOffset = m.visibleBounds().Min
+
+			return m, nil
+		case "n":
+			m.showNumbers = !m.showNumbers
+			m.viewOffset = m.visibleBounds().Min
+
+			return m, nil
+		case "v":
+			m.colored = !m.colored
+
+			m.notifTime = time.Now()
+			m.notifMessage = "switched to monochrome preview"
+			if m.colored {
+				m.notifMessage = "switched to colored preview"
+			}
+
+			return m, nil
+		case "b":
+			m.spaceBlanks = !m.spaceBlanks
+
+			m.notifTime = time.Now()
+			m.notifMessage = "showing blank characters as ⠀"
+			if m.spaceBlanks {
+				m.notifMessage = "showing blank characters as spaces"
+			}
+
+			return m, nil
+		case "m":
+			m.heatmap = !m.heatmap
+
+			m.notifTime = time.Now()
+			m.notifMessage = "hid the dot density heatmap"
+			if m.heatmap {
+				m.notifMessage = "showing the dot density heatmap"
+			}
+
+			return m, nil
+		case "s":
+			if m.graphics == graphicsNone {
+				m.notifTime = time.Now()
+				m.notifMessage = "terminal does not support sixel or kitty graphics"
+
+				return m, nil
+			}
+
+			m.showSource = !m.showSource
+			m.sourceImage = ""
+
+			return m, nil
+		case "tab":
+			if m.previousFileName == "" {
+				return m, nil
+			}
+
+			m.switchToFile(m.previousFileName)
+			return m, nil
+		case "ctrl+n", "ctrl+p":
+			if len(m._argFiles) < 2 {
+				return m, nil
+			}
+
+			step := 1
+			if msg.String() == "ctrl+p" {
+				step = len(m._argFiles) - 1
+			}
+
+			m._argIndex = (m._argIndex + step) % len(m._argFiles)
+			m.switchToFile(m._argFiles[m._argIndex])
+
+			return m, nil
+		case "R":
+			if m.processError != nil {
+				return m, nil
+			}
+
+			if m.inverted {
+				m.notifTime = time.Now()
+				m.notifMessage = strings.ToLower(InvertedReadingError.Error())
+
+				return m, nil
+			}
+
+			m.confirmingReset = true
+			return m, nil
+		case "t":
+			if m.processError != nil {
+				return m, nil
+			}
+
+			fileName, paddingX, paddingY := m.fileName, m.paddingX, m.paddingY
+			toggleCmd := m.runCanvasOp("toggling the padding", "finished toggling the padding!", func() error {
+				return togglePaddingState(fileName, paddingX, paddingY)
+			})
+
+			return m, toggleCmd
+		case "P":
+			if m.processError != nil || m.updateViewError != nil {
+				return m, nil
+			}
+
+			m.repadOpts = repadOptionStore{repadding: true, input: m.repadOpts.input}
+			m.repadOpts.input.SetValue("")
+
+			focusCmd := m.repadOpts.input.Focus()
+			return m, focusCmd
+		}
+	}
+
+	return m, nil
+}
+
+const undoHistoryLimit = 10
+
+// The file contents from before a destructive operation, restored with undo.
+type canvasSnapshot struct {
+	fileName string
+	data     []byte
+
+	// Set instead of data for buffered edits, which are undone in memory.
+	buffer *editBuffer
+}
+
+// Posted by runCanvasOp once the operation is done with the file, with the file as read
+// afterwards.
+type canvasOpDoneMsg struct {
+	snapshot     canvasSnapshot
+	pixelsBefore [][]rune
+	doneMessage  string
+	preview      updatePreviewMsg
+	err          error
+}
+
+// Runs a slow operation on the file off the update loop, so large canvases do not freeze
+// the preview. The write signal is held until canvasOpDoneMsg, so the watch tick skips
+// reading the file mid-write. The operation must not touch the model, Update takes the
+// result from canvasOpDoneMsg.
+func (m *previewArtModel) runCanvasOp(working string, doneMessage string, op func() error) tea.Cmd {
+	pixelsBefore := readCanvasPixels(m.fileName, m.readOptions())
+	snapshot := readSnapshot(m.fileName)
+	src := m.previewSource()
+
+	m.writeSignal <- struct{}{}
+	m.working = working
+
+	return func() tea.Msg {
+		if err := op(); err != nil {
+			return canvasOpDoneMsg{snapshot, pixelsBefore, doneMessage, updatePreviewMsg{}, err}
+		}
+
+		preview := readPreview(src, previewCacheKey{}, updatePreviewMsg{})
+		return canvasOpDoneMsg{snapshot, pixelsBefore, doneMessage, preview, nil}
+	}
+}
+
+// Returns a snapshot without data if the file cannot be read, which is never pushed.
+func readSnapshot(fileName string) canvasSnapshot {
+	data, _ := os.ReadFile(fileName)
+	return canvasSnapshot{fileName: fileName, data: data}
+}
+
+func (m *previewArtModel) pushUndo(snapshot canvasSnapshot) {
+	if snapshot.data == nil && snapshot.buffer == nil {
+		return
+	}
+
+	m.undoHistory = append(m.undoHistory, snapshot)
+	if len(m.undoHistory) > undoHistoryLimit {
+		m.undoHistory = m.undoHistory[len(m.undoHistory)-undoHistoryLimit:]
+	}
+}
+
+// Returns nil if the file cannot be read, which counts as a blank canvas.
+func readCanvasPixels(fileName string, opts convert.ReadOptions) [][]rune {
+	canvas, err := readCanvasFile(fileName, opts)
+	if err != nil {
+		return nil
+	}
+
+	return canvas.pixels
+}
+
+// Counts the dots that were shaded and cleared between two versions of a canvas.
+// Characters outside of either canvas count as blank.
+func inkDelta(before [][]rune, after [][]rune) (int, int) {
+	dotsAt := func(pixels [][]rune, charX int, charY int) int {
+		if charY >= len(pixels) || charX >= len(pixels[charY]) {
+			return 0
+		}
+
+		dots, _ := convert.RuneToBits(pixels[charY][charX])
+		return dots
+	}
+
+	added, removed := 0, 0
+	for charY := range max(len(before), len(after)) {
+		lineLength := 0
+		if charY < len(before) {
+			lineLength = len(before[charY])
+		}
+
+		if charY < len(after) {
+			lineLength = max(lineLength, len(after[charY]))
+		}
+
+		for charX := range lineLength {
+			dotsBefore := dotsAt(before, charX, charY)
+			dotsAfter := dotsAt(after, charX, charY)
+
+			added += bits.OnesCount(uint(dotsAfter &^ dotsBefore))
+			removed += bits.OnesCount(uint(dotsBefore &^ dotsAfter))
+		}
+	}
+
+	return added, removed
+}
+
+func (m *previewArtModel) inkDeltaText(pixelsBefore [][]rune) string {
+	added, removed := inkDelta(pixelsBefore, readCanvasPixels(m.fileName, m.readOptions()))
+	return fmt.Sprintf(" (+%v/-%v)", added, removed)
+}
+
+// Where the old top-left character lands on the resized canvas, in characters. Negative
+// when the canvas shrinks towards the anchor.
+func resizeAnchorOffset(resizeX int, resizeY int, anchor image.Point) image.Point {
+	return image.Pt(resizeX*anchor.X/2, resizeY*anchor.Y/2)
+}
+
+// Overlays the old canvas, placed at offset, on the resized one. Characters that are kept
+// show as is, dropped ones as "x", and added ones as "+". Each axis can grow or shrink
+// independently, so a corner can be in neither canvas, and is left blank.
+func resizePreviewText(pixels [][]rune, oldSize image.Point, newSize image.Point, offset image.Point) string {
+	oldBounds := image.Rectangle{Max: oldSize}.Add(offset)
+	newBounds := image.Rectangle{Max: newSize}
+	union := oldBounds.Union(newBounds)
+
+	builder := strings.Builder{}
+	for y := union.Min.Y; y < union.Max.Y; y += 1 {
+		if y != union.Min.Y {
+			builder.WriteRune('\n')
+		}
+
+		for x := union.Min.X; x < union.Max.X; x += 1 {
+			cell := image.Pt(x, y)
+			inOld, inNew := cell.In(oldBounds), cell.In(newBounds)
+
+			switch {
+			case inOld && inNew:
+				pixel := cell.Sub(offset)
+				if pixel.Y < len(pixels) && pixel.X < len(pixels[pixel.Y]) {
+					builder.WriteRune(pixels[pixel.Y][pixel.X])
+				} else {
+					builder.WriteRune(convert.BrailleLookup[0])
+				}
+			case inOld:
+				builder.WriteRune('x')
+			case inNew:
+				builder.WriteRune('+')
+			default:
+				builder.WriteRune(' ')
+			}
+		}
+	}
+
+	return builder.String()
+}
+
+var (
+	focusedAxisStyle   = lipgloss.NewStyle().Bold(true).Reverse(true)
+	unfocusedAxisStyle = lipgloss.NewStyle().Faint(true)
+)
+
+// Both axes with their signed change, the one being resized highlighted.
+func (opts resizeOptionStore) axisLabels() string {
+	widthStyle, heightStyle := focusedAxisStyle, unfocusedAxisStyle
+	if opts.toResizeHeight {
+		widthStyle, heightStyle = unfocusedAxisStyle, focusedAxisStyle
+	}
+
+	return fmt.Sprintf(
+		"%v  %v",
+		widthStyle.Render(fmt.Sprintf(" WIDTH %+d ", opts.inputs[0])),
+		heightStyle.Render(fmt.Sprintf(" HEIGHT %+d ", opts.inputs[1])),
+	)
+}
+
+func (opts resizeOptionStore) anchorName() string {
+	vertical := [3]string{"top", "middle", "bottom"}[opts.anchor.Y]
+	horizontal := [3]string{"left", "center", "right"}[opts.anchor.X]
+
+	if opts.anchor == image.Pt(1, 1) {
+		return "center"
+	}
+
+	return vertical + " " + horizontal
+}
+
+func resizeCanvas(fileName string, paddingX int, paddingY int, resizeX int, resizeY int, anchor image.Point) error {
+	if resizeX == 0 && resizeY == 0 {
+		return nil
+	}
+
+	fileStats, err := os.Stat(fileName)
+	if err != nil {
+		return decodeError{FileDoesNotExistError}
+	}
+
+	if time.Since(fileStats.ModTime()) < time.Second {
+		return silentError{err}
+	}
+
+	m, err := getCanvasMeasurement(fileName, paddingX, paddingY)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Open(fileName)
+	if err != nil {
+		return decodeError{FileDoesNotExistError}
+	}
+
+	oldImage, err := png.Decode(file)
+	file.Close()
+
+	if err != nil {
+		return decodeError{err}
+	}
+
+	newCharsX := m.CharsX + resizeX
+	newCharsY := m.CharsY + resizeY
+
+	newImageWidth := newCharsX * m.BrailleW
+	newImageHeight := newCharsY * m.BrailleH
+
+	if m.IsUnpadded {
+		newImageWidth += 1
+		newImageHeight += 1
+	}
+
+	// Moving the art keeps the checkerboard of the new canvas, so only the shaded dots are moved.
+	if offset := resizeAnchorOffset(resizeX, resizeY, anchor); offset != (image.Point{}) {
+		newMeasure := m
+		newMeasure.CharsX = newCharsX
+		newMeasure.CharsY = newCharsY
+
+		newImage := convert.NewCanvasImage(newImageWidth, newImageHeight, paddingX, paddingY, m.IsUnpadded, m.CellH, m.Background, m.Align)
+		newDotBounds := image.Rect(0, 0, newCharsX*convert.BRAILLE_WIDTH, newCharsY*m.CellH)
+
+		forEachContentDot(m, oldImage, paddingX, paddingY, func(dotX int, dotY int, c color.Color) {
+			newDot := image.Pt(dotX+offset.X*convert.BRAILLE_WIDTH, dotY+offset.Y*m.CellH)
+			if !newDot.In(newDotBounds) {
+				return
+			}
+
+			x, y := newMeasure.DotPixel(newDot.X, newDot.Y)
+			newImage.Set(x, y, c)
+		})
+
+		return writeCanvasImage(fileName, newImage, paddingX, paddingY, m.CellH, m.Background, m.Align, m.Ink)
+	}
+
+	newImage := image.NewNRGBA(image.Rect(0, 0, newImageWidth, newImageHeight))
+	if resizeX > 0 || resizeY > 0 {
+		defaultCanvas := convert.NewCanvasImage(newImage.Bounds().Dx(), newImage.Bounds().Dy(), paddingX, paddingY, m.IsUnpadded, m.CellH, m.Background, m.Align)
+		draw.Draw(newImage, newImage.Bounds(), defaultCanvas, image.Point{}, draw.Src)
+	}
+
+	draw.Draw(
+		newImage,
+		image.Rect(0, 0, min(m.CharsX, newCharsX)*m.BrailleW, min(m.CharsY, newCharsY)*m.BrailleH),
+		oldImage,
+		image.Point{},
+		draw.Src,
+	)
+
+	if err := backupCanvas(fileName); err != nil {
+		return err
+	}
+
+	file, err = os.Create(fileName)
+	if err != nil {
+		return err
+	}
+
+	encodeError := encodeCanvas(file, newImage, paddingX, paddingY, m.CellH, m.Background, m.Align, m.Ink)
+	return encodeError
+}
+
+func resetCanvas(fileName string, paddingX int, paddingY int) error {
+	fileStats, err := os.Stat(fileName)
+	if err != nil {
+		return decodeError{FileDoesNotExistError}
+	}
+
+	if time.Since(fileStats.ModTime()) < time.Second {
+		return silentError{err}
+	}
+
+	m, err := getCanvasMeasurement(fileName, paddingX, paddingY)
+	if err != nil {
+		return err
+	}
+
+	newImage := convert.NewCanvasImage(m.ImageWidth, m.ImageHeight, paddingX, paddingY, m.IsUnpadded, m.CellH, m.Background, m.Align)
+
+	if err := backupCanvas(fileName); err != nil {
+		return err
+	}
+
+	file, err := os.Create(fileName)
+	if err != nil {
+		return err
+	}
+
+	defer file.Close()
+
+	encodeError := encodeCanvas(file, newImage, paddingX, paddingY, m.CellH, m.Background, m.Align, m.Ink)
+	return encodeError
+}
+
+// Spaced blanks are read back as blank characters by importPixelData.
+func exportBraille(fileName string, pixels [][]rune, header string, trim bool, spaceBlanks bool, overwrite bool) error {
+	if len(pixels) == 0 || len(pixels[0]) == 0 {
+		return fmt.Errorf("Nothing to export: empty canvas.")
+	}
+
+	if trim {
+		pixels = trimTrailingBlanks(pixels)
+	}
+
+	if spaceBlanks {
+		pixels = blanksAsSpaces(pixels)
+	}
+
+	_, err := os.Stat(fileName)
+	if err == nil && !overwrite {
+		return ExportFileExistsError
+	}
+
+	builder := bytes.Buffer{}
+	if header != "" {
+		builder.WriteString(header)
+		builder.WriteRune('\n')
+	}
+
+	builder.WriteString(pixelsToText(pixels))
+
+	err = os.WriteFile(fileName, builder.Bytes(), 0644)
+	if err != nil {
+		return fmt.Errorf("Error writing to the file: %v", err)
+	}
+
+	return nil
+}
+
+// Only trailing blanks are removed: blank characters at the end of each line, then
+// wholly blank lines at the bottom. Blank lines in between are kept.
+func trimTrailingBlanks(pixels [][]rune) [][]rune {
+	trimmed := make([][]rune, len(pixels))
+	for i, line := range pixels {
+		lineEnd := len(line)
+		for lineEnd > 0 && line[lineEnd-1] == convert.BrailleLookup[0] {
+			lineEnd -= 1
 		}
+
+		trimmed[i] = line[:lineEnd]
 	}
 
-	return m, nil
+	for len(trimmed) > 0 && len(trimmed[len(trimmed)-1]) == 0 {
+		trimmed = trimmed[:len(trimmed)-1]
+	}
+
+	return trimmed
 }
 
-func resizeCanvas(fileName string, paddingX int, paddingY int, resizeX int, resizeY int) error {
-	if resizeX == 0 && resizeY == 0 {
-		return nil
+// For fonts that draw the blank braille character with a box or a different width.
+func blanksAsSpaces(pixels [][]rune) [][]rune {
+	spaced := make([][]rune, len(pixels))
+	for i, line := range pixels {
+		spaced[i] = make([]rune, len(line))
+		for j, pixel := range line {
+			if pixel == convert.BrailleLookup[0] {
+				pixel = ' '
+			}
+
+			spaced[i][j] = pixel
+		}
 	}
 
-	fileStats, err := os.Stat(fileName)
-	if err != nil {
-		return decodeError{FileDoesNotExistError}
+	return spaced
+}
+
+func pixelsToText(pixels [][]rune) string {
+	builder := strings.Builder{}
+	for i, line := range pixels {
+		if i != 0 {
+			builder.WriteRune('\n')
+		}
+
+		for _, pixel := range line {
+			builder.WriteRune(pixel)
+		}
 	}
 
-	if time.Since(fileStats.ModTime()) < time.Second {
-		return silentError{err}
+	return builder.String()
+}
+
+func pixelsToColoredText(pixels [][]rune, colors [][]color.Color) string {
+	builder := strings.Builder{}
+	for i, line := range pixels {
+		if i != 0 {
+			builder.WriteRune('\n')
+		}
+
+		for j, pixel := range line {
+			if i >= len(colors) || j >= len(colors[i]) || colors[i][j] == nil {
+				builder.WriteRune(pixel)
+				continue
+			}
+
+			r, g, b, _ := colors[i][j].RGBA()
+			hexColor := fmt.Sprintf("#%02x%02x%02x", r>>8, g>>8, b>>8)
+
+			builder.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color(hexColor)).Render(string(pixel)))
+		}
 	}
 
-	m, err := getCanvasMeasurement(fileName, paddingX, paddingY)
-	if err != nil {
-		return err
+	return builder.String()
+}
+
+// From sparse to dense, for characters with 1 to 8 shaded dots. Blank characters are left as is.
+var heatmapColors = []lipgloss.Color{
+	"#8fb3e8", "#6fc3d6", "#6fd1a4", "#a6dc6c", "#e0dc52", "#f2b544", "#ec7f3f", "#d9493b",
+}
+
+// Six-dot characters are spread over the same colors, so a full character is always the densest.
+func heatmapStyle(dots int, cellH int) lipgloss.Style {
+	maxDots := convert.BRAILLE_WIDTH * cellH
+	level := (dots*len(heatmapColors) + maxDots - 1) / maxDots
+
+	return lipgloss.NewStyle().
+		Background(heatmapColors[level-1]).
+		Foreground(lipgloss.Color("#000000"))
+}
+
+// Tints every character by how many of its dots are shaded.
+func pixelsToHeatmapText(pixels [][]rune, cellH int) string {
+	builder := strings.Builder{}
+	for i, line := range pixels {
+		if i != 0 {
+			builder.WriteRune('\n')
+		}
+
+		for _, pixel := range line {
+			dotBits, _ := convert.RuneToBits(pixel)
+			dots := bits.OnesCount(uint(dotBits))
+			if dots == 0 {
+				builder.WriteRune(pixel)
+				continue
+			}
+
+			builder.WriteString(heatmapStyle(dots, cellH).Render(string(pixel)))
+		}
 	}
 
-	file, err := os.Open(fileName)
-	if err != nil {
-		return decodeError{FileDoesNotExistError}
+	return builder.String()
+}
+
+func heatmapLegend(cellH int) string {
+	builder := strings.Builder{}
+	builder.WriteString("density (shaded dots): 0")
+
+	for dots := 1; dots <= convert.BRAILLE_WIDTH*cellH; dots += 1 {
+		builder.WriteString(heatmapStyle(dots, cellH).Render(fmt.Sprintf(" %v", dots)))
 	}
 
-	oldImage, err := png.Decode(file)
-	file.Close()
+	return builder.String()
+}
 
-	if err != nil {
-		return decodeError{err}
+// Bounds are in braille characters. Returns false if every character is blank.
+func contentBounds(pixels [][]rune) (image.Rectangle, bool) {
+	bounds := image.Rectangle{}
+	hasContent := false
+
+	for charY, line := range pixels {
+		for charX, pixel := range line {
+			if pixel == convert.BrailleLookup[0] {
+				continue
+			}
+
+			charRect := image.Rect(charX, charY, charX+1, charY+1)
+			if !hasContent {
+				bounds = charRect
+				hasContent = true
+			} else {
+				bounds = bounds.Union(charRect)
+			}
+		}
 	}
 
-	newCharsX := m.charsX + resizeX
-	newCharsY := m.charsY + resizeY
+	return bounds, hasContent
+}
 
-	newImageWidth := newCharsX * m.brailleW
-	newImageHeight := newCharsY * m.brailleH
+func cropPixels[T any](pixels [][]T, bounds image.Rectangle) [][]T {
+	cropped := make([][]T, 0, bounds.Dy())
+	for _, line := range pixels[bounds.Min.Y:bounds.Max.Y] {
+		cropped = append(cropped, line[bounds.Min.X:bounds.Max.X])
+	}
 
-	if m.isUnpadded {
-		newImageWidth += 1
-		newImageHeight += 1
+	return cropped
+}
+
+// Shrinks the canvas by zoom in both axes. A dot is shaded if any of the dots it covers is.
+// Each zoomed character covers zoom x zoom characters of the original.
+func zoomOutPixels(pixels [][]rune, zoom int, cellH int) [][]rune {
+	if len(pixels) == 0 {
+		return pixels
 	}
 
-	newImage := image.NewNRGBA(image.Rect(0, 0, newImageWidth, newImageHeight))
-	if resizeX > 0 || resizeY > 0 {
-		defaultCanvas := newCanvasImage(newImage.Bounds().Dx(), newImage.Bounds().Dy(), paddingX, paddingY, m.isUnpadded)
-		draw.Draw(newImage, newImage.Bounds(), defaultCanvas, image.Point{}, draw.Src)
+	charsX := (len(pixels[0]) + zoom - 1) / zoom
+	charsY := (len(pixels) + zoom - 1) / zoom
+
+	dotsW := len(pixels[0]) * convert.BRAILLE_WIDTH
+	dotsH := len(pixels) * cellH
+
+	isShaded := func(dotX int, dotY int) bool {
+		if dotX >= dotsW || dotY >= dotsH {
+			return false
+		}
+
+		brailleIdx, _ := convert.RuneToBits(pixels[dotY/cellH][dotX/convert.BRAILLE_WIDTH])
+		return brailleIdx&convert.DotBit(dotX%convert.BRAILLE_WIDTH, dotY%cellH) != 0
 	}
 
-	draw.Draw(
-		newImage,
-		image.Rect(0, 0, min(m.charsX, newCharsX)*m.brailleW, min(m.charsY, newCharsY)*m.brailleH),
-		oldImage,
-		image.Point{},
-		draw.Src,
+	zoomed := make([][]rune, charsY)
+	for charY := range zoomed {
+		zoomed[charY] = make([]rune, charsX)
+
+		for charX := range zoomed[charY] {
+			brailleIdx := 0
+
+			for brailleYOff := range cellH {
+				for brailleXOff := range convert.BRAILLE_WIDTH {
+					dotX := (charX*convert.BRAILLE_WIDTH + brailleXOff) * zoom
+					dotY := (charY*cellH + brailleYOff) * zoom
+
+				coveredDots:
+					for yOff := range zoom {
+						for xOff := range zoom {
+							if isShaded(dotX+xOff, dotY+yOff) {
+								brailleIdx |= convert.DotBit(brailleXOff, brailleYOff)
+								break coveredDots
+							}
+						}
+					}
+				}
+			}
+
+			zoomed[charY][charX] = convert.BitsToRune(brailleIdx)
+		}
+	}
+
+	return zoomed
+}
+
+// Each zoomed character takes the first color of the characters it covers.
+func zoomOutColors(colors [][]color.Color, zoom int) [][]color.Color {
+	if len(colors) == 0 {
+		return nil
+	}
+
+	zoomed := make([][]color.Color, (len(colors)+zoom-1)/zoom)
+	for charY := range zoomed {
+		zoomed[charY] = make([]color.Color, (len(colors[0])+zoom-1)/zoom)
+
+		for charX := range zoomed[charY] {
+		coveredChars:
+			for y := charY * zoom; y < min((charY+1)*zoom, len(colors)); y += 1 {
+				for x := charX * zoom; x < min((charX+1)*zoom, len(colors[y])); x += 1 {
+					if colors[y][x] != nil {
+						zoomed[charY][charX] = colors[y][x]
+						break coveredChars
+					}
+				}
+			}
+		}
+	}
+
+	return zoomed
+}
+
+// Lines taken by everything in the preview other than the canvas itself, its border and
+// scroll arrows included. Measured around an empty canvas, as the tooltips wrap on narrow
+// terminals.
+func (m *previewArtModel) chromeHeight() int {
+	emptyCanvas := lipgloss.JoinVertical(lipgloss.Center, "^", previewBorder.Render(""), "v")
+	return lipgloss.Height(m.previewView(emptyCanvas)) - 1
+}
+
+// Returns the part of the canvas that fits in the terminal, in braille characters.
+// The whole canvas is shown until the terminal size is known.
+func (m *previewArtModel) visibleBounds() image.Rectangle {
+	pixels, _ := m.displayedPixels()
+	if len(pixels) == 0 {
+		return image.Rectangle{}
+	}
+
+	charsX, charsY := len(pixels[0]), len(pixels)
+	if m.windowWidth == 0 || m.windowHeight == 0 {
+		return image.Rect(0, 0, charsX, charsY)
+	}
+
+	guidesW, guidesH := m.guidesSize(charsX, charsY)
+
+	visibleW := min(max(m.windowWidth-4-guidesW, 1), charsX)
+	visibleH := min(max(m.windowHeight-m.chromeHeight()-guidesH, 1), charsY)
+
+	offset := image.Pt(
+		min(max(m.viewOffset.X, 0), charsX-visibleW),
+		min(max(m.viewOffset.Y, 0), charsY-visibleH),
 	)
 
-	file, err = os.Create(fileName)
-	if err != nil {
-		return err
+	return image.Rectangle{offset, offset.Add(image.Pt(visibleW, visibleH))}
+}
+
+func (m *previewArtModel) guidesSize(charsX int, charsY int) (int, int) {
+	if m.showNumbers {
+		return guideLabelWidth(charsY*max(m.zoom, 1)) + 1, guideLabelWidth((charsX - 1) * max(m.zoom, 1))
 	}
 
-	encodeError := png.Encode(file, newImage)
-	return encodeError
+	if !m.showGuides {
+		return 0, 0
+	}
+
+	return guideLabelWidth(charsY*max(m.zoom, 1)) + 1, 2
 }
 
-func exportBraille(fileName string, pixels [][]rune) error {
-	_, err := os.Stat(fileName)
-	if err == nil {
-		return fmt.Errorf("File already exists.")
+// Smallest part of the canvas worth scrolling around in, in braille characters.
+const (
+	minVisibleCharsX = 8
+	minVisibleCharsY = 2
+)
+
+// Returns the terminal size the preview needs, and whether the terminal is smaller than that.
+// Larger canvases are scrolled, so only the smallest useful viewport counts.
+func (m *previewArtModel) terminalTooSmall() (image.Point, bool) {
+	pixels, _ := m.displayedPixels()
+	if len(pixels) == 0 || m.windowWidth == 0 || m.windowHeight == 0 {
+		return image.Point{}, false
 	}
 
-	builder := bytes.Buffer{}
-	for _, pixel := range pixels[0] {
-		builder.WriteRune(pixel)
+	charsX, charsY := len(pixels[0]), len(pixels)
+	guidesW, guidesH := m.guidesSize(charsX, charsY)
+
+	needed := image.Pt(
+		min(charsX, minVisibleCharsX)+4+guidesW,
+		min(charsY, minVisibleCharsY)+m.chromeHeight()+guidesH,
+	)
+
+	return needed, m.windowWidth < needed.X || m.windowHeight < needed.Y
+}
+
+// The selected characters, clamped to the canvas. Returns false without a selection.
+func (m *previewArtModel) selection() (image.Rectangle, bool) {
+	if !m.selecting || len(m.pixels) == 0 {
+		return image.Rectangle{}, false
 	}
 
-	for _, line := range pixels[1:] {
-		builder.WriteRune('\n')
-		for _, pixel := range line {
+	selection := image.Rectangle{m.selectionAnchor, m.cursor}.Canon()
+	selection.Max = selection.Max.Add(image.Pt(1, 1))
+	selection = selection.Intersect(image.Rect(0, 0, len(m.pixels[0]), len(m.pixels)))
+
+	return selection, !selection.Empty()
+}
+
+// The canvas as rendered at the current zoom level. Colors are nil when not available.
+func (m *previewArtModel) displayedPixels() ([][]rune, [][]color.Color) {
+	colors := m.colors
+	if len(colors) != len(m.pixels) {
+		colors = nil
+	}
+
+	if m.zoom <= 1 {
+		return m.pixels, colors
+	}
+
+	return zoomOutPixels(m.pixels, m.zoom, m.cellH), zoomOutColors(colors, m.zoom)
+}
+
+func (m *previewArtModel) scrollToCursor() {
+	visible := m.visibleBounds()
+
+	if m.cursor.X < visible.Min.X {
+		m.viewOffset.X = m.cursor.X
+	} else if m.cursor.X >= visible.Max.X {
+		m.viewOffset.X = visible.Min.X + m.cursor.X - visible.Max.X + 1
+	}
+
+	if m.cursor.Y < visible.Min.Y {
+		m.viewOffset.Y = m.cursor.Y
+	} else if m.cursor.Y >= visible.Max.Y {
+		m.viewOffset.Y = visible.Min.Y + m.cursor.Y - visible.Max.Y + 1
+	}
+
+	m.viewOffset = m.visibleBounds().Min
+}
+
+var cursorStyle = lipgloss.NewStyle().Reverse(true)
+
+// The cursor flips the highlight of the selection, so it stays visible inside it.
+func pixelsToTextWithCursor(pixels [][]rune, cursor image.Point, selection image.Rectangle) string {
+	builder := strings.Builder{}
+	for i, line := range pixels {
+		if i != 0 {
+			builder.WriteRune('\n')
+		}
+
+		for j, pixel := range line {
+			if at := image.Pt(j, i); (at == cursor) != at.In(selection) {
+				builder.WriteString(cursorStyle.Render(string(pixel)))
+				continue
+			}
+
 			builder.WriteRune(pixel)
 		}
 	}
 
-	err = os.WriteFile(fileName, builder.Bytes(), 0644)
-	if err != nil {
-		return fmt.Errorf("Error writing to the file: %v", err)
+	return builder.String()
+}
+
+func (m *previewArtModel) viewportView() string {
+	pixels, colors := m.displayedPixels()
+	visible := m.visibleBounds()
+
+	visiblePixels := cropPixels(pixels, visible)
+	if m.spaceBlanks {
+		visiblePixels = blanksAsSpaces(visiblePixels)
 	}
 
-	return nil
+	selection, hasSelection := m.selection()
+	selection = selection.Sub(visible.Min)
+
+	renderedCanvas := pixelsToText(visiblePixels)
+	if m.editing {
+		renderedCanvas = pixelsToTextWithCursor(visiblePixels, m.cursor.Sub(visible.Min), selection)
+	} else if hasSelection && m.zoom <= 1 {
+		renderedCanvas = pixelsToTextWithCursor(visiblePixels, image.Pt(-1, -1), selection)
+	} else if m.heatmap {
+		renderedCanvas = pixelsToHeatmapText(visiblePixels, m.cellH)
+	} else if m.colored && colors != nil {
+		renderedCanvas = pixelsToColoredText(visiblePixels, cropPixels(colors, visible))
+	}
+
+	borderedCanvas := previewBorder.Render(renderedCanvas)
+	if m.showNumbers {
+		borderedCanvas = withLineNumbers(borderedCanvas, visible, max(m.zoom, 1))
+	} else if m.showGuides {
+		borderedCanvas = withGuides(borderedCanvas, visible, max(m.zoom, 1))
+	}
+
+	if visible == image.Rect(0, 0, len(pixels[0]), len(pixels)) {
+		return borderedCanvas
+	}
+
+	indicator := func(hasMore bool, arrow string) string {
+		if hasMore {
+			return arrow
+		}
+
+		return " "
+	}
+
+	middle := lipgloss.JoinHorizontal(
+		lipgloss.Center,
+		indicator(visible.Min.X > 0, "<"),
+		borderedCanvas,
+		indicator(visible.Max.X < len(pixels[0]), ">"),
+	)
+
+	return lipgloss.JoinVertical(
+		lipgloss.Center,
+		indicator(visible.Min.Y > 0, "^"),
+		middle,
+		indicator(visible.Max.Y < len(pixels), "v"),
+	)
 }
 
-var (
-	previewBorder      = lipgloss.NewStyle().Border(lipgloss.InnerHalfBlockBorder())
-	whiteSpaceWithX    = lipgloss.WithWhitespaceChars("x")
-	whiteSpaceWithPlus = lipgloss.WithWhitespaceChars("+")
+const guideLabelEvery = 5
 
-	erroredCanvas = previewBorder.Render("xxxxx\nxxxxx\nxxxxx\nxxxxx\nxxxxx")
-)
+var guideStyle = lipgloss.NewStyle().Faint(true)
 
-func (m *previewArtModel) View() string {
-	renderedPixels := func() string {
-		if len(m.pixels) == 0 {
-			return erroredCanvas
+func guideLabelWidth(maxLabel int) int {
+	return len(strconv.Itoa(max(maxLabel, 0)))
+}
+
+// Adds rulers above and left of a bordered canvas, labeling every 5th column and row.
+// Labels are in canvas characters, so they follow panning and zooming.
+func withGuides(borderedCanvas string, visible image.Rectangle, zoom int) string {
+	labelW := guideLabelWidth((visible.Max.Y - 1) * zoom)
+
+	rowLabels := []string{""}
+	for y := visible.Min.Y; y < visible.Max.Y; y += 1 {
+		label := "·"
+		if y%guideLabelEvery == 0 {
+			label = strconv.Itoa(y * zoom)
 		}
 
-		if !m.rOpts.resizing {
-			builder := strings.Builder{}
-			for _, pixel := range m.pixels[0] {
-				builder.WriteRune(pixel)
-			}
+		rowLabels = append(rowLabels, label)
+	}
 
-			for _, line := range m.pixels[1:] {
-				builder.WriteRune('\n')
-				for _, pixel := range line {
-					builder.WriteRune(pixel)
-				}
-			}
+	rowRuler := guideStyle.
+		Width(labelW + 1).
+		PaddingRight(1).
+		Align(lipgloss.Right).
+		Render(strings.Join(rowLabels, "\n"))
 
-			return previewBorder.Render(builder.String())
+	labels := []rune(strings.Repeat(" ", visible.Dx()))
+	ticks := []rune(strings.Repeat("·", visible.Dx()))
+
+	labelEnd := 0
+	for i := range visible.Dx() {
+		x := visible.Min.X + i
+		if x%guideLabelEvery != 0 {
+			continue
 		}
 
-		measure, err := getCanvasMeasurement(m.fileName, m.paddingX, m.paddingY)
-		if err != nil {
+		ticks[i] = '|'
+
+		label := strconv.Itoa(x * zoom)
+		if i < labelEnd || i+len(label) > len(labels) {
+			continue
+		}
+
+		copy(labels[i:], []rune(label))
+		labelEnd = i + len(label) + 1
+	}
+
+	// The border takes one more column before the first character.
+	indent := strings.Repeat(" ", labelW+2)
+	columnRuler := guideStyle.Render(indent + string(labels) + "\n" + indent + string(ticks))
+
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		columnRuler,
+		lipgloss.JoinHorizontal(lipgloss.Top, rowRuler, borderedCanvas),
+	)
+}
+
+// Numbers every row on the left, and every column on top with its digits stacked downwards,
+// so each number sits right above its column.
+func withLineNumbers(borderedCanvas string, visible image.Rectangle, zoom int) string {
+	labelW := guideLabelWidth((visible.Max.Y - 1) * zoom)
+
+	rowLabels := []string{""}
+	for y := visible.Min.Y; y < visible.Max.Y; y += 1 {
+		rowLabels = append(rowLabels, strconv.Itoa(y*zoom))
+	}
+
+	rowRuler := guideStyle.
+		Width(labelW + 1).
+		PaddingRight(1).
+		Align(lipgloss.Right).
+		Render(strings.Join(rowLabels, "\n"))
+
+	digits := guideLabelWidth((visible.Max.X - 1) * zoom)
+	digitRows := make([][]rune, digits)
+	for i := range digitRows {
+		digitRows[i] = []rune(strings.Repeat(" ", visible.Dx()))
+	}
+
+	for i := range visible.Dx() {
+		label := fmt.Sprintf("%*d", digits, (visible.Min.X+i)*zoom)
+		for digit, char := range label {
+			digitRows[digit][i] = char
+		}
+	}
+
+	// The border takes one more column before the first character.
+	indent := strings.Repeat(" ", labelW+2)
+
+	columnLines := make([]string, 0, digits)
+	for _, digitRow := range digitRows {
+		columnLines = append(columnLines, indent+string(digitRow))
+	}
+
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		guideStyle.Render(strings.Join(columnLines, "\n")),
+		lipgloss.JoinHorizontal(lipgloss.Top, rowRuler, borderedCanvas),
+	)
+}
+
+var (
+	previewBorder = lipgloss.NewStyle().Border(lipgloss.InnerHalfBlockBorder())
+
+	erroredCanvas = previewBorder.Render("xxxxx\nxxxxx\nxxxxx\nxxxxx\nxxxxx")
+)
+
+func (m *previewArtModel) View() string {
+	// The border and the braille grid get mangled when the terminal wraps them.
+	if needed, tooSmall := m.terminalTooSmall(); tooSmall {
+		return lipgloss.JoinVertical(
+			lipgloss.Left,
+			"",
+			fmt.Sprintf("Terminal too small to preview %v", m.fileName),
+			fmt.Sprintf("(need %v×%v, is %v×%v)", needed.X, needed.Y, m.windowWidth, m.windowHeight),
+			"",
+			"(resize the terminal, ctrl-c to exit)",
+			"",
+		)
+	}
+
+	renderedPixels := func() string {
+		if len(m.pixels) == 0 {
 			return erroredCanvas
 		}
 
-		newCharsX := m.rOpts.inputs[0] + measure.charsX
-		newCharsY := m.rOpts.inputs[1] + measure.charsY
-
-		renderedDimensionX := min(newCharsX, measure.charsX)
-		renderedDimensionY := min(newCharsY, measure.charsY)
+		// The image takes the place of the braille characters, except while editing.
+		if m.showSource && m.sourceImage != "" && !m.rOpts.resizing && !m.editing {
+			return m.sourceImage
+		}
 
-		whiteSpaceStyleX := whiteSpaceWithPlus
-		whiteSpaceStyleY := whiteSpaceWithPlus
+		if !m.rOpts.resizing {
+			// Clears a kitty image left over from toggling it off.
+			if m.graphics == graphicsKitty {
+				return kittyDeleteImages + m.viewportView()
+			}
 
-		if newCharsX < measure.charsX {
-			whiteSpaceStyleX = whiteSpaceWithX
+			return m.viewportView()
 		}
 
-		if newCharsY < measure.charsY {
-			whiteSpaceStyleY = whiteSpaceWithX
+		measure, err := getCanvasMeasurement(m.fileName, m.paddingX, m.paddingY)
+		if err != nil {
+			return erroredCanvas
 		}
 
-		builder := strings.Builder{}
-		for _, pixel := range m.pixels[0][:renderedDimensionX] {
-			builder.WriteRune(pixel)
-		}
+		newCharsX := m.rOpts.inputs[0] + measure.CharsX
+		newCharsY := m.rOpts.inputs[1] + measure.CharsY
 
-		for _, line := range m.pixels[1:renderedDimensionY] {
-			builder.WriteRune('\n')
-			for _, pixel := range line[:renderedDimensionX] {
-				builder.WriteRune(pixel)
-			}
-		}
+		offset := resizeAnchorOffset(m.rOpts.inputs[0], m.rOpts.inputs[1], m.rOpts.anchor)
+		renderedCanvas := resizePreviewText(m.pixels, image.Pt(measure.CharsX, measure.CharsY), image.Pt(newCharsX, newCharsY), offset)
 
-		renderedCanvas := builder.String()
-		if newCharsX > measure.charsX {
-			renderedCanvas = lipgloss.PlaceHorizontal(
-				max(newCharsX, measure.charsX),
-				lipgloss.Left,
-				renderedCanvas,
-				whiteSpaceStyleX,
-			)
-			renderedCanvas = lipgloss.PlaceVertical(
-				max(newCharsY, measure.charsY),
-				lipgloss.Top,
-				renderedCanvas,
-				whiteSpaceStyleY,
-			)
-		} else {
-			renderedCanvas = lipgloss.PlaceVertical(
-				max(newCharsY, measure.charsY),
-				lipgloss.Top,
-				renderedCanvas,
-				whiteSpaceStyleY,
-			)
-			renderedCanvas = lipgloss.PlaceHorizontal(
-				max(newCharsX, measure.charsX),
-				lipgloss.Left,
-				renderedCanvas,
-				whiteSpaceStyleX,
-			)
+		borderedCanvas := previewBorder.Render(renderedCanvas)
+		resizeBounds := image.Rect(0, 0, max(newCharsX, measure.CharsX), max(newCharsY, measure.CharsY))
+		if m.showNumbers {
+			borderedCanvas = withLineNumbers(borderedCanvas, resizeBounds, 1)
+		} else if m.showGuides {
+			borderedCanvas = withGuides(borderedCanvas, resizeBounds, 1)
 		}
 
-		borderedCanvas := previewBorder.Render(renderedCanvas)
 		if m.rOpts.toResizeHeight {
-			return lipgloss.JoinVertical(lipgloss.Center, borderedCanvas, " # \n###")
+			borderedCanvas = lipgloss.JoinVertical(lipgloss.Center, borderedCanvas, " # \n###")
 		} else {
-			return lipgloss.JoinHorizontal(lipgloss.Center, borderedCanvas, " #\n##\n #")
+			borderedCanvas = lipgloss.JoinHorizontal(lipgloss.Center, borderedCanvas, " #\n##\n #")
 		}
+
+		return lipgloss.JoinVertical(lipgloss.Left, m.rOpts.axisLabels(), borderedCanvas)
 	}()
 
+	return m.previewView(renderedPixels)
+}
+
+// Everything in the preview around the rendered canvas, wrapped to the terminal.
+func (m *previewArtModel) previewView(renderedPixels string) string {
 	watchTickerView := "_ watching file /"
 	if !m.watchTicker {
 		watchTickerView = "\\ watching file _"
 	}
 
+	if m.watchPaused {
+		watchTickerView = "= watching paused (p to resume) ="
+	}
+
+	if m.buffer != nil {
+		watchTickerView = fmt.Sprintf("= modified, %v unsaved edits (ctrl+s to save) =", m.buffer.edits)
+	}
+
+	if m.working != "" {
+		watchTickerView = fmt.Sprintf("/ %v…", m.working)
+		if !m.watchTicker {
+			watchTickerView = fmt.Sprintf("\\ %v…", m.working)
+		}
+	}
+
 	if opts := m.exportOpts; opts.exporting {
 		if m.processError != nil {
-			return lipgloss.JoinVertical(
-				lipgloss.Left,
-				"",
-				fmt.Sprintf("Viewing %v", m.fileName),
+			return m.layoutPreview(
 				renderedPixels,
 				watchTickerView,
 				"",
@@ -980,11 +3036,23 @@ func (m *previewArtModel) View() string {
 			)
 		}
 
-		if opts.showConfirmPrompt {
-			return lipgloss.JoinVertical(
-				lipgloss.Left,
+		if opts.showConfirmPrompt && opts.overwriting {
+			return m.layoutPreview(
+				renderedPixels,
+				watchTickerView,
+				"",
+				"Exporting braille characters to file:",
+				"",
+				"  The file exists. Overwrite it?",
+				fmt.Sprintf("  \"%v\"", opts.input.Value()),
+				"",
+				"(exporting) (y to overwrite, n/esc to go back)",
 				"",
-				fmt.Sprintf("Viewing %v", m.fileName),
+			)
+		}
+
+		if opts.showConfirmPrompt {
+			return m.layoutPreview(
 				renderedPixels,
 				watchTickerView,
 				"",
@@ -998,17 +3066,125 @@ func (m *previewArtModel) View() string {
 			)
 		}
 
-		return lipgloss.JoinVertical(
-			lipgloss.Left,
-			"",
-			fmt.Sprintf("Viewing %v", m.fileName),
+		exportTooltip := "(exporting) (enter to continue, tab to change what to export, shift+tab to change the format, ctrl-c to exit program, esc to go back)"
+		if m.confirmingDiscard {
+			exportTooltip = m.discardPromptText()
+		}
+
+		selectionText := ""
+		if selection, hasSelection := m.selection(); hasSelection {
+			selectionText = fmt.Sprintf(
+				"\nOnly the %vx%v selection at %v,%v (arrows while editing to clear it)",
+				selection.Dx(), selection.Dy(), selection.Min.X, selection.Min.Y,
+			)
+		}
+
+		formatText := fmt.Sprintf("Format: %v", opts.format)
+		if opts.format != exportBrailleText {
+			formatText += fmt.Sprintf(", %vx scale (up/down to adjust)", opts.scale)
+			formatText += fmt.Sprintf("\nProportions: %v (ctrl+r to change)", exportAspects[opts.aspect])
+		} else if opts.keepGrid {
+			formatText += ", keeping the full grid (ctrl+t to trim trailing blanks)"
+		} else {
+			formatText += ", trimming trailing blanks (ctrl+t to keep the full grid)"
+		}
+
+		if opts.format == exportBrailleText && !isHTMLFileName(opts.input.Value()) && !isANSIFileName(opts.input.Value()) {
+			if opts.spaceBlanks {
+				formatText += "\nWriting blank characters as spaces (ctrl+b to write ⠀)"
+			} else {
+				formatText += "\nWriting blank characters as ⠀ (ctrl+b to write spaces)"
+			}
+		}
+
+		if opts.format == exportBrailleText && isHTMLFileName(opts.input.Value()) {
+			formatText += "\nWriting an html <pre> block"
+			if opts.htmlLines {
+				formatText += ", one element per line (ctrl+l to turn off)"
+			} else {
+				formatText += " (ctrl+l to wrap each line in an element)"
+			}
+
+			if m.colored {
+				formatText += ", colored"
+			}
+		}
+
+		if opts.format == exportBrailleText && isANSIFileName(opts.input.Value()) {
+			formatText += "\nWriting 24-bit ansi colors, for truecolor terminals (name it .txt for plain text)"
+		}
+
+		return m.layoutPreview(
 			renderedPixels,
 			watchTickerView,
 			"",
 			"Exporting braille characters to file:",
 			fmt.Sprintf("File name: %v", opts.input.View()),
+			fmt.Sprintf("Exporting: %v%v", opts.contentMode, selectionText),
+			formatText,
+			"",
+			exportTooltip,
+			"",
+		)
+	}
+
+	if opts := m.saveOpts; opts.saving {
+		errorText := ""
+		if opts.err != nil {
+			errorText = fmt.Sprintf("  Error saving the file: %v (any key to continue)", opts.err)
+		}
+
+		saveTitle := "Saving a copy of the canvas as:"
+		saveTooltip := "(saving as) (enter to save and preview the copy, ctrl-c to exit program, esc to go back)"
+		if opts.duplicating {
+			saveTitle = "Duplicating the canvas, the original is kept as is:"
+			saveTooltip = "(duplicating) (enter to duplicate and work on the copy, tab to switch back, ctrl-c to exit program, esc to go back)"
+		}
+
+		if m.confirmingDiscard {
+			saveTooltip = m.discardPromptText()
+		}
+
+		return m.layoutPreview(
+			renderedPixels,
+			watchTickerView,
+			"",
+			saveTitle,
+			fmt.Sprintf("File name: %v", opts.input.View()),
+			fmt.Sprintf("Saves to: \"%v\"", m.saveAsFileName()),
+			errorText,
+			"",
+			saveTooltip,
+			"",
+		)
+	}
+
+	if opts := m.repadOpts; opts.repadding {
+		errorText := ""
+		if opts.err != nil {
+			errorText = fmt.Sprintf("  Error re-padding the canvas: %v (any key to continue)", opts.err)
+		}
+
+		repadTooltip := "(re-padding) (enter to re-pad the canvas, ctrl-c to exit program, esc to go back)"
+		if m.confirmingDiscard {
+			repadTooltip = m.discardPromptText()
+		}
+
+		renamesTo := ""
+		if paddingX, paddingY, err := parsePaddingSpec(opts.input.Value()); err == nil {
+			renamesTo = fmt.Sprintf("Renames to: \"%v\"", paddedFileName(m.fileName, paddingX, paddingY))
+		}
+
+		return m.layoutPreview(
+			renderedPixels,
+			watchTickerView,
+			"",
+			fmt.Sprintf("Re-padding the canvas, currently %vx%v:", m.paddingX, m.paddingY),
+			fmt.Sprintf("New padding (<pX>x<pY>): %v", opts.input.View()),
+			renamesTo,
+			errorText,
 			"",
-			"(exporting) (enter to continue, ctrl-c to exit program, esc to go back)",
+			repadTooltip,
 			"",
 		)
 	}
@@ -1019,20 +3195,114 @@ func (m *previewArtModel) View() string {
 			notifMessage = ", " + m.notifMessage
 		}
 
-		tooltipText := "(t to toggle padding, c/C to clean canvas, r to resize canvas, e to export, ctrl-c to exit, esc to go back)"
+		// Only the keys used most, the rest are listed by ? from previewKeybindings.
+		tooltipText := "(space to edit, arrows to pan, u to undo, e to export, ? for help, ctrl-c to exit, esc to go back)"
 		if opts := m.rOpts; opts.resizing {
-			tooltipText = "(resizing) (+/- to adjust canvas, tab to change direction, c to cancel, enter to confirm, esc to go back)"
+			tooltipText = lipgloss.JoinVertical(
+				lipgloss.Left,
+				"(resizing) (+/- to adjust canvas, type a number to set the size, tab to change direction, a/A to change the anchor, c to cancel, enter to confirm, esc to go back)",
+				fmt.Sprintf("anchor: %v", opts.anchorName()),
+			)
+
+			if opts.typedTarget != "" {
+				axisName := "width"
+				if opts.toResizeHeight {
+					axisName = "height"
+				}
+
+				targetText := fmt.Sprintf("target %v: %v characters", axisName, opts.typedTarget)
+				if isWholeNumber(opts.typedTarget) != nil {
+					targetText = fmt.Sprintf("target %v: %v (must be a whole number above zero)", axisName, opts.typedTarget)
+				}
+
+				tooltipText = lipgloss.JoinVertical(lipgloss.Left, tooltipText, targetText)
+			}
+
+			if m.confirmingDiscard {
+				tooltipText = lipgloss.JoinVertical(lipgloss.Left, tooltipText, m.discardPromptText())
+			}
 		}
 
-		return lipgloss.JoinVertical(
+		if m.editing {
+			tooltipText = "(editing) (arrows to move, shift+arrows to select for exporting, 1-8 to toggle dots, f to fill, m to mirror edits, u to undo, space/esc to stop editing)"
+		}
+
+		if m.confirmingReset {
+			tooltipText = "(resetting) Are you sure you want to wipe the canvas? (y/enter to confirm, any other key to go back)"
+		}
+
+		readingText := ""
+		if m.cellH == convert.SIX_DOT_BRAILLE_HEIGHT {
+			readingText = ", dots: 6"
+		}
+
+		if m.inverted {
+			readingText += ", light on dark"
+		}
+
+		if baseReadOptions.Luma {
+			readingText += ", by luminance"
+		}
+
+		if m.symmetry != symmetryNone {
+			readingText += fmt.Sprintf(", mirror: %v", m.symmetry)
+		}
+
+		statusText := fmt.Sprintf("padded?: %v%v, threshold: %v, zoom: 1:%v%v", !m.unpadded, readingText, m.threshold, max(m.zoom, 1), notifMessage)
+		statusText = lipgloss.JoinVertical(
 			lipgloss.Left,
-			"",
-			fmt.Sprintf("Viewing %v", m.fileName),
+			statusText,
+			fmt.Sprintf(
+				"size: %v×%v chars (%v×%v px), shaded dots: %v",
+				m.measure.CharsX, m.measure.CharsY, m.measure.ImageWidth, m.measure.ImageHeight, m.shadedDots,
+			),
+		)
+		if m.heatmap {
+			statusText = lipgloss.JoinVertical(lipgloss.Left, statusText, heatmapLegend(m.cellH))
+		}
+
+		if m.unreadableError != nil {
+			statusText = lipgloss.JoinVertical(
+				lipgloss.Left,
+				statusText,
+				fmt.Sprintf("file unreadable, retrying (showing the last good read): %v", m.unreadableError),
+			)
+		}
+
+		if selection, hasSelection := m.selection(); hasSelection {
+			statusText = lipgloss.JoinVertical(
+				lipgloss.Left,
+				statusText,
+				fmt.Sprintf(
+					"selection: %vx%v chars at %v,%v, exported on its own (arrows while editing to clear)",
+					selection.Dx(), selection.Dy(), selection.Min.X, selection.Min.Y,
+				),
+			)
+		}
+
+		if len(m._argFiles) > 1 {
+			statusText = lipgloss.JoinVertical(
+				lipgloss.Left,
+				statusText,
+				fmt.Sprintf("file %v of %v (ctrl-n/ctrl-p to cycle)", m._argIndex+1, len(m._argFiles)),
+				m.thumbnailStrip(),
+			)
+		}
+
+		if m.previousFileName != "" {
+			statusText = lipgloss.JoinVertical(
+				lipgloss.Left,
+				statusText,
+				fmt.Sprintf("switching files (tab): [%v] / %v", m.fileName, m.previousFileName),
+			)
+		}
+
+		return m.layoutPreview(
 			renderedPixels,
 			watchTickerView,
 			"",
 			tooltipText,
-			fmt.Sprintf("padded?: %v%v", !m.unpadded, notifMessage),
+			statusText,
 		)
 	}
 
@@ -1041,15 +3311,275 @@ func (m *previewArtModel) View() string {
 		watchTickerView = "\\ watching (invalid) file _"
 	}
 
+	if m.watchPaused {
+		watchTickerView = "= watching (invalid) file paused (p to resume) ="
+	}
+
 	errorPrompt := fmt.Sprintf("Error processing the image:\n%v", m.updateViewError)
 	return lipgloss.JoinVertical(
 		lipgloss.Left,
-		fmt.Sprintf("Viewing %v", m.fileName),
+		m.wrapToWindow(fmt.Sprintf("Viewing %v", m.fileName)),
 		renderedPixels,
 		"",
-		watchTickerView,
-		"",
-		errorPrompt,
+		m.wrapToWindow(lipgloss.JoinVertical(lipgloss.Left, watchTickerView, "", errorPrompt, "")),
+	)
+}
+
+// The file name and watch ticker around the canvas, with the prompts below them.
+func (m *previewArtModel) layoutPreview(renderedPixels string, watchTickerView string, below ...string) string {
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
 		"",
+		m.wrapToWindow(fmt.Sprintf("Viewing %v", m.fileName)),
+		renderedPixels,
+		m.wrapToWindow(lipgloss.JoinVertical(lipgloss.Left, append([]string{watchTickerView}, below...)...)),
 	)
 }
+
+// The terminal would cut off long lines, so the tooltips are wrapped instead.
+func (m *previewArtModel) wrapToWindow(text string) string {
+	if m.windowWidth == 0 {
+		return text
+	}
+
+	return lipgloss.NewStyle().Width(m.windowWidth).Render(text)
+}
+
+// Keeps the directory and padding of the previewed file, only the name changes.
+func (m *previewArtModel) saveAsFileName() string {
+	newName := fmt.Sprintf("%v.%vx%v.by.png", m.saveOpts.input.Value(), m.paddingX, m.paddingY)
+	return filepath.Join(filepath.Dir(m.fileName), newName)
+}
+
+// The first free "<prefix>-<n>" next to the previewed file, so duplicating only takes an enter.
+func (m *previewArtModel) duplicatePrefix() string {
+	prefix, _, ok := splitCanvasFileName(m.fileName)
+	if !ok {
+		prefix = strings.TrimSuffix(filepath.Base(m.fileName), filepath.Ext(m.fileName))
+	}
+
+	for n := 2; ; n += 1 {
+		candidate := fmt.Sprintf("%v-%v", prefix, n)
+		newName := fmt.Sprintf("%v.%vx%v.by.png", candidate, m.paddingX, m.paddingY)
+
+		if _, err := os.Stat(filepath.Join(filepath.Dir(m.fileName), newName)); err != nil {
+			return candidate
+		}
+	}
+}
+
+func (m *previewArtModel) updateSaveAs(keyMsg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	opts := &m.saveOpts
+
+	if opts.err != nil {
+		opts.err = nil
+
+		focusCmd := opts.input.Focus()
+		return m, focusCmd
+	}
+
+	if keyMsg.String() != "enter" {
+		var cmd tea.Cmd
+		opts.input, cmd = opts.input.Update(keyMsg)
+
+		return m, cmd
+	}
+
+	if err := isValidFileName(opts.input.Value()); err != nil {
+		opts.err = err
+		return m, nil
+	}
+
+	newFileName := m.saveAsFileName()
+
+	saveErr := error(nil)
+	if isBrailleTextFile(m.fileName) {
+		saveErr = saveTextCanvasAs(m.pixels, newFileName, m.paddingX, m.paddingY, m.cellH)
+	} else {
+		saveErr = saveCanvasAs(m.fileName, newFileName)
+	}
+
+	if saveErr != nil {
+		opts.err = saveErr
+		return m, nil
+	}
+
+	for i, argFile := range m._argFiles {
+		if argFile == m.fileName {
+			m._argFiles[i] = newFileName
+		}
+	}
+
+	// The undo history belongs to the original file, which is left untouched.
+	m.previousFileName = m.fileName
+	m.fileName = newFileName
+	m.undoHistory = nil
+
+	lastPreviewedFile = newFileName
+	recordRecentFile(newFileName)
+
+	m.sourceImage = ""
+	m.loadPixels()
+
+	opts.saving = false
+
+	m.notifTime = time.Now()
+	m.notifMessage = fmt.Sprintf("saved as %v!", newFileName)
+	if opts.duplicating {
+		m.notifMessage = fmt.Sprintf("duplicated as %v, the original is untouched!", newFileName)
+	}
+
+	return m, nil
+}
+
+// Same-padding input closes the prompt without touching the file.
+func (m *previewArtModel) updateRepad(keyMsg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	opts := &m.repadOpts
+
+	if opts.err != nil {
+		opts.err = nil
+
+		focusCmd := opts.input.Focus()
+		return m, focusCmd
+	}
+
+	if keyMsg.String() != "enter" {
+		var cmd tea.Cmd
+		opts.input, cmd = opts.input.Update(keyMsg)
+
+		return m, cmd
+	}
+
+	paddingX, paddingY, err := parsePaddingSpec(opts.input.Value())
+	if err != nil {
+		opts.err = err
+		return m, nil
+	}
+
+	if paddingX == m.paddingX && paddingY == m.paddingY && !m.unpadded {
+		opts.repadding = false
+
+		m.notifTime = time.Now()
+		m.notifMessage = "the canvas already has that padding"
+
+		return m, nil
+	}
+
+	snapshot := readSnapshot(m.fileName)
+
+	m.writeSignal <- struct{}{}
+	newFileName, err := repadCanvas(m.fileName, m.paddingX, m.paddingY, paddingX, paddingY)
+	<-m.writeSignal
+
+	if errors.Is(err, RepaddedFileExistsError) {
+		opts.err = fmt.Errorf("%v already exists", paddedFileName(m.fileName, paddingX, paddingY))
+		return m, nil
+	}
+
+	if err != nil {
+		if _, isSilent := err.(silentError); isSilent {
+			opts.repadding = false
+			m.notifyTooRecent()
+
+			return m, nil
+		}
+
+		return panicMsgModel(err.Error()), nil
+	}
+
+	if newFileName == m.fileName {
+		m.pushUndo(snapshot)
+	} else {
+		for i, argFile := range m._argFiles {
+			if argFile == m.fileName {
+				m._argFiles[i] = newFileName
+			}
+		}
+
+		// The undo history holds the old padding under the old name.
+		m.fileName = newFileName
+		m.undoHistory = nil
+		lastPreviewedFile = newFileName
+	}
+
+	m.sourceImage = ""
+	m.loadPixels()
+
+	opts.repadding = false
+
+	m.notifTime = time.Now()
+	m.notifMessage = fmt.Sprintf("re-padded the canvas to %vx%v!", paddingX, paddingY)
+
+	return m, nil
+}
+
+// Only typed input counts, an opened prompt with nothing typed (or only the suggested
+// duplicate name) is closed right away.
+// Confirmation and error screens are left as they were.
+func (m *previewArtModel) hasPendingInput() bool {
+	if opts := m.rOpts; opts.resizing && (opts.inputs != [2]int{} || opts.typedTarget != "") {
+		return true
+	}
+
+	if opts := m.exportOpts; opts.exporting && !opts.showConfirmPrompt && m.processError == nil {
+		return opts.input.Value() != ""
+	}
+
+	if opts := m.repadOpts; opts.repadding {
+		return opts.err == nil && opts.input.Value() != ""
+	}
+
+	opts := m.saveOpts
+	return opts.saving && opts.err == nil && opts.input.Value() != "" && opts.input.Value() != opts.suggestion
+}
+
+// Pressing ctrl+c again quits without asking.
+func (m *previewArtModel) updateDiscard(keyMsg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	m.confirmingDiscard = false
+
+	switch keyMsg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "y":
+		if m.discardQuits {
+			return m, tea.Quit
+		}
+
+		// Only asked for the buffered edits when leaving the preview, so go on leaving it.
+		if m.buffer != nil && !m.hasPendingInput() {
+			m.buffer = nil
+			return m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+		}
+
+		m.rOpts.resizing = false
+		m.exportOpts.exporting = false
+		m.exportOpts.overwriting = false
+		m.saveOpts.saving = false
+		m.repadOpts.repadding = false
+	}
+
+	return m, nil
+}
+
+func (m *previewArtModel) discardPromptText() string {
+	if m.buffer != nil && !m.hasPendingInput() {
+		if m.discardQuits {
+			return fmt.Sprintf("Discard %v unsaved edits and exit? (y/n, ctrl-c again to exit)", m.buffer.edits)
+		}
+
+		return fmt.Sprintf("Discard %v unsaved edits and go back? (y/n)", m.buffer.edits)
+	}
+
+	if m.discardQuits {
+		return "Discard the unsaved input and exit? (y/n, ctrl-c again to exit)"
+	}
+
+	return "Discard the unsaved input? (y/n)"
+}
+
+// The guard against recently modified files is not an error, but pressing a key
+// without anything happening is confusing.
+func (m *previewArtModel) notifyTooRecent() {
+	m.notifTime = time.Now()
+	m.notifMessage = "file changed too recently, try again"
+}
@@ -0,0 +1,348 @@
+package main
+
+import (
+	"image"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// The read runs while Update keeps handling keys, which the race detector checks.
+func TestWatchTickReadsOffTheModel(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	pixels := testPixels(3, 2)
+	m := newPreviewArtModel(writeTestCanvas(t, pixels, 0, 2))
+
+	_, readCmd := m.Update(watchTickMsg{})
+	if readCmd == nil {
+		t.Fatal("a watch tick did not read the file")
+	}
+
+	msgs := make(chan any)
+	go func() { msgs <- readCmd() }()
+
+	for range 10 {
+		m.Update(tea.WindowSizeMsg{Width: 80, Height: 24})
+		m.View()
+	}
+
+	msg, isUpdateMsg := (<-msgs).(updatePreviewMsg)
+	if !isUpdateMsg {
+		t.Fatalf("a watch tick read %T, want updatePreviewMsg", msg)
+	}
+
+	if _, tickCmd := m.Update(msg); tickCmd == nil {
+		t.Error("the preview stopped watching after a read")
+	}
+
+	if !slices.EqualFunc(m.pixels, pixels, slices.Equal) {
+		t.Errorf("the preview shows %q, want %q", m.pixels, pixels)
+	}
+}
+
+func TestTabKeepsTheFileIfThePreviousOneCannotOpen(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	pixels := testPixels(3, 2)
+	fileName := writeTestCanvas(t, pixels, 0, 2)
+
+	m := newPreviewArtModel(fileName)
+	m.previousFileName = filepath.Join(t.TempDir(), "missing.0x2.by.png")
+
+	m.Update(tea.KeyMsg{Type: tea.KeyTab})
+
+	if m.fileName != fileName {
+		t.Errorf("tab switched to %v, want it to stay on %v", m.fileName, fileName)
+	}
+
+	if m.updateViewError != nil || !slices.EqualFunc(m.pixels, pixels, slices.Equal) {
+		t.Errorf("tab left %q and %v, want the canvas still shown", m.pixels, m.updateViewError)
+	}
+}
+
+// Cleaning, resetting and growing write dark dots on a light background, which would read
+// the other way around.
+func TestCanvasChangesRefusedWhileInverted(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	fileName := writeTestCanvas(t, testPixels(3, 2), 0, 2)
+	m := newPreviewArtModel(fileName)
+	pressKeys(m, "I")
+
+	refused := strings.ToLower(InvertedReadingError.Error())
+	for _, keys := range [][]string{{"c"}, {"C"}, {"R"}, {"r", "5", "enter"}} {
+		m.notifMessage = ""
+		pressKeys(m, keys...)
+
+		if m.notifMessage != refused || m.working != "" || m.confirmingReset {
+			t.Errorf("%q while reading light on dark was not refused", keys)
+		}
+	}
+
+	opts := baseReadOptions
+	opts.Invert = true
+
+	if err := cleanCanvas(fileName, 0, 2, false, opts); err != InvertedReadingError {
+		t.Errorf("cleaning while reading light on dark gave %v, want it refused", err)
+	}
+}
+
+// The tooltip wraps on narrow terminals, which leaves fewer lines for the canvas.
+func TestViewFitsTheTerminal(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	m := newPreviewArtModel(writeTestCanvas(t, testPixels(20, 40), 0, 2))
+
+	for _, width := range []int{40, 80, 400} {
+		m.Update(tea.WindowSizeMsg{Width: width, Height: 30})
+
+		if height := lipgloss.Height(m.View()); height > 30 {
+			t.Errorf("the preview takes %v lines of a %vx30 terminal", height, width)
+		}
+	}
+
+	m.Update(tea.WindowSizeMsg{Width: 40, Height: 30})
+	narrow := m.visibleBounds()
+
+	m.Update(tea.WindowSizeMsg{Width: 400, Height: 30})
+	if wide := m.visibleBounds(); narrow.Dy() >= wide.Dy() {
+		t.Errorf("%v rows are shown on a narrow terminal and %v on a wide one, want fewer", narrow.Dy(), wide.Dy())
+	}
+}
+
+func TestExportBrailleEmptyCanvas(t *testing.T) {
+	for _, pixels := range [][][]rune{nil, {}, {{}}} {
+		fileName := filepath.Join(t.TempDir(), "empty.txt")
+
+		if err := exportBraille(fileName, pixels, "", false, false, false); err == nil {
+			t.Errorf("exporting %q gave no error", pixels)
+		}
+
+		if _, err := os.Stat(fileName); !os.IsNotExist(err) {
+			t.Errorf("exporting %q wrote the file", pixels)
+		}
+	}
+}
+
+func TestTrimTrailingBlanks(t *testing.T) {
+	pixels := [][]rune{
+		[]rune("⠁⠀⠂⠀⠀"),
+		[]rune("⠀⠀⠀⠀⠀"),
+		[]rune("⠀⠃⠀⠀⠀"),
+		[]rune("⣿⣿⣿⣿⠁"),
+		[]rune("⠀⠀⠀⠀⠀"),
+		[]rune("⠀⠀⠀⠀⠀"),
+	}
+
+	want := [][]rune{
+		[]rune("⠁⠀⠂"),
+		{},
+		[]rune("⠀⠃"),
+		[]rune("⣿⣿⣿⣿⠁"),
+	}
+
+	if trimmed := trimTrailingBlanks(pixels); !slices.EqualFunc(trimmed, want, slices.Equal) {
+		t.Errorf("trimmed to %q, want %q", trimmed, want)
+	}
+
+	if blank := trimTrailingBlanks([][]rune{[]rune("⠀⠀"), []rune("⠀⠀")}); len(blank) != 0 {
+		t.Errorf("trimmed a blank canvas to %q, want nothing", blank)
+	}
+}
+
+func TestExportBrailleTrimmed(t *testing.T) {
+	pixels := [][]rune{
+		[]rune("⠁⠀⠀"),
+		[]rune("⠀⠀⠀"),
+		[]rune("⠀⠂⠀"),
+		[]rune("⠀⠀⠀"),
+	}
+
+	tests := []struct {
+		name string
+		trim bool
+		want string
+	}{
+		{"trimmed", true, "⠁\n\n⠀⠂"},
+		{"whole grid", false, "⠁⠀⠀\n⠀⠀⠀\n⠀⠂⠀\n⠀⠀⠀"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fileName := filepath.Join(t.TempDir(), "art.txt")
+			if err := exportBraille(fileName, pixels, "", test.trim, false, false); err != nil {
+				t.Fatal(err)
+			}
+
+			data, err := os.ReadFile(fileName)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if string(data) != test.want {
+				t.Errorf("exported %q, want %q", data, test.want)
+			}
+		})
+	}
+}
+
+func TestSplitCanvasFileName(t *testing.T) {
+	tests := []struct {
+		fileName   string
+		wantPrefix string
+		wantSpec   string
+		wantOk     bool
+	}{
+		{"art.0x2.by.png", "art", "0x2", true},
+		{"my.cool.art.0x2.by.png", "my.cool.art", "0x2", true},
+		{"trailing..1x3.by.png", "trailing.", "1x3", true},
+		{"has.by.inside.2x0.by.png", "has.by.inside", "2x0", true},
+		{"3x3.0x2.by.png", "3x3", "0x2", true},
+		{"some.dir/art.12x4.by.png", "art", "12x4", true},
+		{"art.by.png", "", "", false},
+		{".0x2.by.png", "", "", false},
+		{"art.0x2.png", "", "", false},
+		{"art.0xa.by.png", "", "", false},
+		{"art.0x2.by.png.bak", "", "", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.fileName, func(t *testing.T) {
+			prefix, spec, ok := splitCanvasFileName(test.fileName)
+			if prefix != test.wantPrefix || spec != test.wantSpec || ok != test.wantOk {
+				t.Errorf("got %q, %q, %v, want %q, %q, %v", prefix, spec, ok, test.wantPrefix, test.wantSpec, test.wantOk)
+			}
+		})
+	}
+}
+
+func TestPaddingFromFileName(t *testing.T) {
+	tests := []struct {
+		fileName string
+		want     image.Point
+		wantErr  error
+	}{
+		{"my.cool.art.12x3.by.png", image.Pt(12, 3), nil},
+		{"art.1.2x0.by.png", image.Pt(2, 0), nil},
+		{"art.by.png", image.Point{}, InvalidFileNameError},
+		{"art.-1x0.by.png", image.Point{}, InvalidFileNameError},
+	}
+
+	for _, test := range tests {
+		t.Run(test.fileName, func(t *testing.T) {
+			paddingX, paddingY, err := paddingFromFileName(test.fileName)
+			if got := image.Pt(paddingX, paddingY); got != test.want || err != test.wantErr {
+				t.Errorf("got %v, %v, want %v, %v", got, err, test.want, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestResizePreviewText(t *testing.T) {
+	pixels := [][]rune{
+		[]rune("⠁⠂⠃"),
+		[]rune("⠄⠅⠆"),
+	}
+
+	tests := []struct {
+		name    string
+		newSize image.Point
+		offset  image.Point
+		want    string
+	}{
+		{"grow both", image.Pt(4, 3), image.Point{}, "⠁⠂⠃+\n⠄⠅⠆+\n++++"},
+		{"shrink both", image.Pt(2, 1), image.Point{}, "⠁⠂x\nxxx"},
+		{"shrink width, grow height", image.Pt(2, 3), image.Point{}, "⠁⠂x\n⠄⠅x\n++ "},
+		{"grow width, shrink height", image.Pt(4, 1), image.Point{}, "⠁⠂⠃+\nxxx "},
+		{"grow both sides", image.Pt(5, 2), image.Pt(1, 0), "+⠁⠂⠃+\n+⠄⠅⠆+"},
+		{"shrink from the top left", image.Pt(2, 1), image.Pt(-1, -1), "xxx\nx⠅⠆"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := resizePreviewText(pixels, image.Pt(3, 2), test.newSize, test.offset); got != test.want {
+				t.Errorf("got\n%v\nwant\n%v", got, test.want)
+			}
+		})
+	}
+}
+
+// Runs a watch tick through Update, with the read it starts.
+func watchTick(t *testing.T, m *previewArtModel) tea.Model {
+	t.Helper()
+
+	_, readCmd := m.Update(watchTickMsg{})
+	if readCmd == nil {
+		t.Fatal("a watch tick did not read the file")
+	}
+
+	model, _ := m.Update(readCmd())
+	return model
+}
+
+func TestTruncatedFileKeepsTheLastRender(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	pixels := testPixels(3, 2)
+	fileName := writeTestCanvas(t, pixels, 0, 2)
+
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := newPreviewArtModel(fileName)
+
+	// Caught halfway through being written.
+	if err := os.WriteFile(fileName, data[:len(data)/2], 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if model := watchTick(t, m); model != m {
+		t.Fatalf("a truncated file switched the preview to %T", model)
+	}
+
+	if m.unreadableError == nil {
+		t.Error("a truncated file is not reported as unreadable")
+	}
+
+	if !slices.EqualFunc(m.pixels, pixels, slices.Equal) {
+		t.Errorf("the preview shows %q, want the last render %q", m.pixels, pixels)
+	}
+
+	if err := os.WriteFile(fileName, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	watchTick(t, m)
+
+	if m.unreadableError != nil {
+		t.Errorf("the file is still unreadable once written: %v", m.unreadableError)
+	}
+}
+
+func TestTruncatedFileFailsTheFirstLoad(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	fileName := writeTestCanvas(t, testPixels(3, 2), 0, 2)
+
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(fileName, data[:len(data)/2], 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	m := newPreviewArtModel(fileName)
+	if model := watchTick(t, m); model == m {
+		t.Error("a file that never read kept the preview open")
+	}
+}
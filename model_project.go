@@ -0,0 +1,273 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const projectFileSuffix = ".benday.json"
+
+var (
+	NoProjectMembersError = errors.New("Project has no member files.")
+)
+
+type bendayProject struct {
+	Name     string `json:"name"`
+	PaddingX *int   `json:"paddingX,omitempty"`
+	PaddingY *int   `json:"paddingY,omitempty"`
+	CharsX   int    `json:"charsX,omitempty"`
+	CharsY   int    `json:"charsY,omitempty"`
+
+	Members []projectMember `json:"members"`
+}
+
+type projectMember struct {
+	File  string `json:"file"`
+	Frame *int   `json:"frame,omitempty"`
+	Layer string `json:"layer,omitempty"`
+}
+
+func (member projectMember) role() string {
+	roles := []string{}
+	if member.Frame != nil {
+		roles = append(roles, fmt.Sprintf("frame %v", *member.Frame))
+	}
+
+	if member.Layer != "" {
+		roles = append(roles, fmt.Sprintf("layer \"%v\"", member.Layer))
+	}
+
+	if len(roles) == 0 {
+		return "no role"
+	}
+
+	return strings.Join(roles, ", ")
+}
+
+func readProject(projectPath string) (bendayProject, error) {
+	project := bendayProject{}
+
+	data, err := os.ReadFile(projectPath)
+	if err != nil {
+		return project, FileDoesNotExistError
+	}
+
+	if err := json.Unmarshal(data, &project); err != nil {
+		return project, fmt.Errorf("Error reading the project file: %w", err)
+	}
+
+	if len(project.Members) == 0 {
+		return project, NoProjectMembersError
+	}
+
+	if paddingX := project.PaddingX; paddingX != nil && *paddingX < 0 {
+		return project, fmt.Errorf("Shared paddingX is invalid: %w", NotAPositiveNumberError)
+	}
+
+	if paddingY := project.PaddingY; paddingY != nil && *paddingY < 0 {
+		return project, fmt.Errorf("Shared paddingY is invalid: %w", NotAPositiveNumberError)
+	}
+
+	return project, nil
+}
+
+// Member paths are relative to the directory of the project file.
+func (project bendayProject) memberPath(projectPath string, member projectMember) string {
+	if filepath.IsAbs(member.File) {
+		return member.File
+	}
+
+	return filepath.Join(filepath.Dir(projectPath), member.File)
+}
+
+func (project bendayProject) validateMember(memberPath string) error {
+	if _, err := os.Stat(memberPath); err != nil {
+		return FileDoesNotExistError
+	}
+
+	paddingX, paddingY, err := readCanvasPadding(memberPath)
+	if err != nil {
+		return err
+	}
+
+	if project.PaddingX != nil && *project.PaddingX != paddingX {
+		return fmt.Errorf("Padding X is %v, but the project declares %v.", paddingX, *project.PaddingX)
+	}
+
+	if project.PaddingY != nil && *project.PaddingY != paddingY {
+		return fmt.Errorf("Padding Y is %v, but the project declares %v.", paddingY, *project.PaddingY)
+	}
+
+	measure, err := getCanvasMeasurement(memberPath, paddingX, paddingY)
+	if err != nil {
+		return err
+	}
+
+	if project.CharsX != 0 && project.CharsX != measure.CharsX {
+		return fmt.Errorf("Width is %v characters, but the project declares %v.", measure.CharsX, project.CharsX)
+	}
+
+	if project.CharsY != 0 && project.CharsY != measure.CharsY {
+		return fmt.Errorf("Height is %v characters, but the project declares %v.", measure.CharsY, project.CharsY)
+	}
+
+	return nil
+}
+
+type projectModel struct {
+	projectPath string
+	project     bendayProject
+	memberErrs  []error
+
+	focused int
+	err     error
+}
+
+func newProjectModel(projectPath string) *projectModel {
+	newModel := &projectModel{projectPath: projectPath}
+
+	project, err := readProject(projectPath)
+	if err != nil {
+		newModel.err = err
+		return newModel
+	}
+
+	newModel.project = project
+	newModel.memberErrs = make([]error, len(project.Members))
+
+	for i, member := range project.Members {
+		memberPath := project.memberPath(projectPath, member)
+		newModel.memberErrs[i] = project.validateMember(memberPath)
+	}
+
+	return newModel
+}
+
+func (m *projectModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m *projectModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	keyMsg, isKeyMsg := msg.(tea.KeyMsg)
+	if !isKeyMsg {
+		return m, nil
+	}
+
+	switch keyMsg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "esc":
+		startModel := newBendayStartModel()
+		return startModel, startModel.Init()
+	}
+
+	if m.err != nil {
+		startModel := newBendayStartModel()
+		return startModel, startModel.Init()
+	}
+
+	members := m.project.Members
+
+	switch keyMsg.String() {
+	case "?":
+		return newHelpOverlay(m), nil
+	case "tab", "down", "ctrl+n", "j":
+		m.focused = (m.focused + 1) % len(members)
+
+	case "shift+tab", "up", "ctrl+p", "k":
+		m.focused -= 1
+
+		if m.focused < 0 {
+			m.focused = len(members) - 1
+		}
+
+	case "enter":
+		if m.memberErrs[m.focused] != nil {
+			return m, nil
+		}
+
+		memberPath := m.project.memberPath(m.projectPath, members[m.focused])
+
+		previewModel := newPreviewArtModel(memberPath)
+		previewModel._fromProject = m.projectPath
+
+		return previewModel, previewModel.Init()
+	}
+
+	return m, nil
+}
+
+func (m *projectModel) View() string {
+	if m.err != nil {
+		return lipgloss.JoinVertical(
+			lipgloss.Left,
+			"",
+			fmt.Sprintf("Error opening the project \"%v\":", m.projectPath),
+			m.err.Error(),
+			"",
+			"(opening project failed) (any key to go back)",
+		)
+	}
+
+	projectName := m.project.Name
+	if projectName == "" {
+		projectName = filepath.Base(m.projectPath)
+	}
+
+	sharedSettings := []string{}
+	if m.project.PaddingX != nil || m.project.PaddingY != nil {
+		paddingX, paddingY := "any", "any"
+		if m.project.PaddingX != nil {
+			paddingX = strconv.Itoa(*m.project.PaddingX)
+		}
+
+		if m.project.PaddingY != nil {
+			paddingY = strconv.Itoa(*m.project.PaddingY)
+		}
+
+		sharedSettings = append(sharedSettings, fmt.Sprintf("padding %vx%v", paddingX, paddingY))
+	}
+
+	if m.project.CharsX != 0 || m.project.CharsY != 0 {
+		sharedSettings = append(sharedSettings, fmt.Sprintf("size %vx%v chars", m.project.CharsX, m.project.CharsY))
+	}
+
+	if len(sharedSettings) == 0 {
+		sharedSettings = append(sharedSettings, "none")
+	}
+
+	members := make([]string, len(m.project.Members))
+	for i, member := range m.project.Members {
+		selectedStr := " "
+		if m.focused == i {
+			selectedStr = "+"
+		}
+
+		status := "ok"
+		if err := m.memberErrs[i]; err != nil {
+			status = fmt.Sprintf("invalid: %v", err)
+		}
+
+		members[i] = fmt.Sprintf("  [%v] %v (%v) (%v)", selectedStr, member.File, member.role(), status)
+	}
+
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		"",
+		fmt.Sprintf("  Project: %v", projectName),
+		fmt.Sprintf("  Shared settings: %v", strings.Join(sharedSettings, ", ")),
+		"",
+		strings.Join(members, "\n"),
+		"",
+		"(project) (up/down to select, enter to preview member, ? for help, esc to go back, ctrl-c to exit program)",
+		"",
+	)
+}
@@ -0,0 +1,220 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const recentFilesLimit = 10
+
+var (
+	NoRecentFilesError = errors.New("No files have been opened yet.")
+)
+
+var missingRecentFileStyle = lipgloss.NewStyle().Faint(true)
+
+func recentFilesPath() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(configDir, "benday", "recent.json"), nil
+}
+
+// Returns an empty list if there is no recent files list yet.
+func readRecentFiles() []string {
+	recentPath, err := recentFilesPath()
+	if err != nil {
+		return nil
+	}
+
+	data, err := os.ReadFile(recentPath)
+	if err != nil {
+		return nil
+	}
+
+	recentFiles := []string{}
+	if err := json.Unmarshal(data, &recentFiles); err != nil {
+		return nil
+	}
+
+	return recentFiles
+}
+
+func writeRecentFiles(recentFiles []string) error {
+	recentPath, err := recentFilesPath()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(recentPath), 0755); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(recentFiles, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(recentPath, data, 0644)
+}
+
+// Failing to record is not worth interrupting the preview for, so errors are ignored.
+func recordRecentFile(fileName string) {
+	absPath, err := filepath.Abs(fileName)
+	if err != nil {
+		return
+	}
+
+	recentFiles := slices.DeleteFunc(readRecentFiles(), func(recentFile string) bool {
+		return recentFile == absPath
+	})
+
+	recentFiles = append([]string{absPath}, recentFiles...)
+	if len(recentFiles) > recentFilesLimit {
+		recentFiles = recentFiles[:recentFilesLimit]
+	}
+
+	writeRecentFiles(recentFiles)
+}
+
+func removeRecentFile(fileName string) {
+	recentFiles := slices.DeleteFunc(readRecentFiles(), func(recentFile string) bool {
+		return recentFile == fileName
+	})
+
+	writeRecentFiles(recentFiles)
+}
+
+type recentModel struct {
+	recentFiles []string
+	missing     []bool
+
+	focused int
+	err     error
+}
+
+func newRecentModel() *recentModel {
+	newModel := &recentModel{}
+	newModel.loadRecentFiles()
+
+	return newModel
+}
+
+func (m *recentModel) loadRecentFiles() {
+	m.recentFiles = readRecentFiles()
+	m.missing = make([]bool, len(m.recentFiles))
+	m.err = nil
+
+	for i, recentFile := range m.recentFiles {
+		_, err := os.Stat(recentFile)
+		m.missing[i] = err != nil
+	}
+
+	if len(m.recentFiles) == 0 {
+		m.err = NoRecentFilesError
+	}
+
+	m.focused = min(m.focused, max(len(m.recentFiles)-1, 0))
+}
+
+func (m *recentModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m *recentModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	keyMsg, isKeyMsg := msg.(tea.KeyMsg)
+	if !isKeyMsg {
+		return m, nil
+	}
+
+	switch keyMsg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "esc":
+		startModel := newBendayStartModel()
+		return startModel, startModel.Init()
+	}
+
+	if m.err != nil {
+		startModel := newBendayStartModel()
+		return startModel, startModel.Init()
+	}
+
+	switch keyMsg.String() {
+	case "?":
+		return newHelpOverlay(m), nil
+	case "tab", "down", "ctrl+n", "j":
+		m.focused = (m.focused + 1) % len(m.recentFiles)
+
+	case "shift+tab", "up", "ctrl+p", "k":
+		m.focused -= 1
+
+		if m.focused < 0 {
+			m.focused = len(m.recentFiles) - 1
+		}
+
+	case "enter":
+		recentFile := m.recentFiles[m.focused]
+
+		if m.missing[m.focused] {
+			removeRecentFile(recentFile)
+			m.loadRecentFiles()
+
+			return m, nil
+		}
+
+		previewModel := newPreviewArtModel(recentFile)
+		return previewModel, previewModel.Init()
+	}
+
+	return m, nil
+}
+
+func (m *recentModel) View() string {
+	if m.err != nil {
+		return lipgloss.JoinVertical(
+			lipgloss.Left,
+			"",
+			"Cannot show recent files:",
+			m.err.Error(),
+			"",
+			"(opening recent failed) (any key to go back)",
+		)
+	}
+
+	recentFiles := make([]string, len(m.recentFiles))
+	for i, recentFile := range m.recentFiles {
+		selectedStr := " "
+		if m.focused == i {
+			selectedStr = "+"
+		}
+
+		entry := fmt.Sprintf("  [%v] %v", selectedStr, recentFile)
+		if m.missing[i] {
+			entry = missingRecentFileStyle.Render(entry + " (missing)")
+		}
+
+		recentFiles[i] = entry
+	}
+
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		"",
+		"  Recently opened files:",
+		"",
+		strings.Join(recentFiles, "\n"),
+		"",
+		"(recent) (up/down to select, enter to preview or remove a missing file, ? for help, esc to go back, ctrl-c to exit program)",
+		"",
+	)
+}
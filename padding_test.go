@@ -0,0 +1,119 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/noAbbreviation/benday/convert"
+)
+
+// Every character differs from its neighbours, so moved or dropped dots show up.
+func testPixels(charsX int, charsY int) [][]rune {
+	pixels := make([][]rune, charsY)
+	for y := range charsY {
+		pixels[y] = make([]rune, charsX)
+		for x := range charsX {
+			pixels[y][x] = convert.BrailleLookup[(x*31+y*17+5)%len(convert.BrailleLookup)]
+		}
+	}
+
+	return pixels
+}
+
+// Older than a second, so the guard against recently modified files lets it be changed.
+func ageTestFile(t *testing.T, fileName string) {
+	t.Helper()
+
+	past := time.Now().Add(-5 * time.Second)
+	if err := os.Chtimes(fileName, past, past); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func writeTestCanvas(t *testing.T, pixels [][]rune, paddingX int, paddingY int) string {
+	t.Helper()
+
+	fileName := filepath.Join(t.TempDir(), fmt.Sprintf("test.%vx%v.by.png", paddingX, paddingY))
+	img := convert.NewPixelsImage(pixels, paddingX, paddingY, convert.BRAILLE_HEIGHT, convert.AlignStart, baseReadOptions.Ink)
+
+	err := writeCanvasImage(fileName, img, paddingX, paddingY, convert.BRAILLE_HEIGHT, convert.BackgroundCheckerboard, convert.AlignStart, baseReadOptions.Ink)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ageTestFile(t, fileName)
+	return fileName
+}
+
+func readTestImage(t *testing.T, fileName string) *image.NRGBA {
+	t.Helper()
+
+	file, err := os.Open(fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer file.Close()
+
+	img, err := png.Decode(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	nrgba := image.NewNRGBA(img.Bounds())
+	draw.Draw(nrgba, nrgba.Bounds(), img, img.Bounds().Min, draw.Src)
+
+	return nrgba
+}
+
+func sameImage(a *image.NRGBA, b *image.NRGBA) bool {
+	if a.Bounds() != b.Bounds() {
+		return false
+	}
+
+	for y := a.Bounds().Min.Y; y < a.Bounds().Max.Y; y += 1 {
+		for x := a.Bounds().Min.X; x < a.Bounds().Max.X; x += 1 {
+			if color.NRGBAModel.Convert(a.At(x, y)) != color.NRGBAModel.Convert(b.At(x, y)) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+func TestTogglePaddingTwiceIsIdentity(t *testing.T) {
+	paddings := []image.Point{{0, 0}, {0, 2}, {2, 0}, {1, 3}, {3, 3}}
+
+	// 1x5 characters at 1x3 padding and 2x5 at 3x3 are unpadded canvases whose size is
+	// also divisible by the padded cell size.
+	sizes := []image.Point{{1, 5}, {2, 5}, {4, 3}}
+
+	for _, padding := range paddings {
+		for _, size := range sizes {
+			t.Run(fmt.Sprintf("%vx%v padding, %vx%v chars", padding.X, padding.Y, size.X, size.Y), func(t *testing.T) {
+				fileName := writeTestCanvas(t, testPixels(size.X, size.Y), padding.X, padding.Y)
+				original := readTestImage(t, fileName)
+
+				for range 2 {
+					if err := togglePaddingState(fileName, padding.X, padding.Y); err != nil {
+						t.Fatal(err)
+					}
+
+					ageTestFile(t, fileName)
+				}
+
+				if toggled := readTestImage(t, fileName); !sameImage(original, toggled) {
+					t.Errorf("toggling twice changed the canvas, %v became %v", original.Bounds(), toggled.Bounds())
+				}
+			})
+		}
+	}
+}
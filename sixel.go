@@ -0,0 +1,142 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"os"
+	"strings"
+)
+
+const sixelScale = 4
+
+// Detection is done through the environment since querying the terminal
+// for its device attributes would fight with bubbletea over stdin.
+func terminalSupportsSixel() bool {
+	term := strings.ToLower(os.Getenv("TERM"))
+	if strings.Contains(term, "sixel") {
+		return true
+	}
+
+	switch term {
+	case "foot", "foot-extra", "mlterm", "yaft-256color", "contour":
+		return true
+	}
+
+	switch os.Getenv("TERM_PROGRAM") {
+	case "WezTerm", "mlterm", "iTerm.app", "contour":
+		return true
+	}
+
+	return false
+}
+
+// Colors are quantized into a 6x6x6 cube so the palette never exceeds
+// the 256 registers most sixel terminals provide.
+func encodeSixel(img image.Image, scale int) string {
+	bounds := img.Bounds()
+	width := bounds.Dx() * scale
+	height := bounds.Dy() * scale
+
+	if width == 0 || height == 0 {
+		return ""
+	}
+
+	paletteIdx := func(c color.Color) int {
+		pxColor := color.NRGBAModel.Convert(c).(color.NRGBA)
+		if 3*uint32(pxColor.A) < 0xff {
+			return -1
+		}
+
+		r := int(pxColor.R) * 5 / 0xff
+		g := int(pxColor.G) * 5 / 0xff
+		b := int(pxColor.B) * 5 / 0xff
+
+		return r*36 + g*6 + b
+	}
+
+	indexAt := func(x, y int) int {
+		return paletteIdx(img.At(bounds.Min.X+x/scale, bounds.Min.Y+y/scale))
+	}
+
+	usedColors := [216]bool{}
+	for y := range bounds.Dy() {
+		for x := range bounds.Dx() {
+			if idx := paletteIdx(img.At(bounds.Min.X+x, bounds.Min.Y+y)); idx >= 0 {
+				usedColors[idx] = true
+			}
+		}
+	}
+
+	builder := strings.Builder{}
+	builder.WriteString("\x1bP0;1q")
+	builder.WriteString(fmt.Sprintf("\"1;1;%v;%v", width, height))
+
+	for idx, used := range usedColors {
+		if !used {
+			continue
+		}
+
+		r, g, b := idx/36, (idx/6)%6, idx%6
+		builder.WriteString(fmt.Sprintf("#%v;2;%v;%v;%v", idx, r*100/5, g*100/5, b*100/5))
+	}
+
+	bandBits := make([]byte, width)
+	for bandY := 0; bandY < height; bandY += 6 {
+		for idx, used := range usedColors {
+			if !used {
+				continue
+			}
+
+			hasPixels := false
+			for x := range width {
+				bandBits[x] = 0
+
+				for bit := range 6 {
+					y := bandY + bit
+					if y >= height {
+						break
+					}
+
+					if indexAt(x, y) == idx {
+						bandBits[x] |= 1 << bit
+						hasPixels = true
+					}
+				}
+			}
+
+			if !hasPixels {
+				continue
+			}
+
+			builder.WriteString(fmt.Sprintf("#%v", idx))
+			writeSixelRun(&builder, bandBits)
+			builder.WriteByte('$')
+		}
+
+		builder.WriteByte('-')
+	}
+
+	builder.WriteString("\x1b\\")
+	return builder.String()
+}
+
+func writeSixelRun(builder *strings.Builder, bandBits []byte) {
+	for i := 0; i < len(bandBits); {
+		runLength := 1
+		for i+runLength < len(bandBits) && bandBits[i+runLength] == bandBits[i] {
+			runLength += 1
+		}
+
+		sixelChar := rune(63 + bandBits[i])
+		if runLength > 3 {
+			builder.WriteString(fmt.Sprintf("!%v%c", runLength, sixelChar))
+		} else {
+			for range runLength {
+				builder.WriteRune(sixelChar)
+			}
+		}
+
+		i += runLength
+	}
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Size of a thumbnail in braille characters, not counting its border.
+const (
+	thumbnailCharsX = 8
+	thumbnailCharsY = 2
+)
+
+var (
+	thumbnailStyle        = lipgloss.NewStyle().Border(lipgloss.NormalBorder()).Faint(true)
+	currentThumbnailStyle = lipgloss.NewStyle().Border(lipgloss.ThickBorder())
+)
+
+// Shrinks a canvas until it fits in a thumbnail, with the dots of the covered characters combined.
+func thumbnailPixels(pixels [][]rune, cellH int) [][]rune {
+	if len(pixels) == 0 {
+		return pixels
+	}
+
+	zoom := max(
+		(len(pixels[0])+thumbnailCharsX-1)/thumbnailCharsX,
+		(len(pixels)+thumbnailCharsY-1)/thumbnailCharsY,
+		1,
+	)
+
+	return zoomOutPixels(pixels, zoom, cellH)
+}
+
+// Only the files that were already opened have a thumbnail, the others are left blank
+// instead of being decoded all at once.
+func (m *previewArtModel) recordThumbnail() {
+	if len(m._argFiles) < 2 || len(m.pixels) == 0 {
+		return
+	}
+
+	if m.thumbnails == nil {
+		m.thumbnails = map[string][][]rune{}
+	}
+
+	m.thumbnails[m.fileName] = thumbnailPixels(m.pixels, m.cellH)
+}
+
+// The thumbnails that fit in the terminal, keeping the current file in view.
+func (m *previewArtModel) thumbnailStrip() string {
+	thumbnailW := thumbnailCharsX + 3
+	fitting := max(m.windowWidth/thumbnailW, 1)
+	if m.windowWidth == 0 {
+		fitting = len(m._argFiles)
+	}
+
+	first := min(max(m._argIndex-fitting/2, 0), max(len(m._argFiles)-fitting, 0))
+	last := min(first+fitting, len(m._argFiles))
+
+	rendered := []string{}
+	for i := first; i < last; i += 1 {
+		lines := []string{}
+		for _, line := range m.thumbnails[m._argFiles[i]] {
+			lines = append(lines, string(line)+strings.Repeat(" ", thumbnailCharsX-len(line)))
+		}
+
+		if len(lines) == 0 {
+			lines = append(lines, fmt.Sprintf("%-*v", thumbnailCharsX, "unopened"))
+		}
+
+		for len(lines) < thumbnailCharsY {
+			lines = append(lines, strings.Repeat(" ", thumbnailCharsX))
+		}
+
+		style := thumbnailStyle
+		if i == m._argIndex {
+			style = currentThumbnailStyle
+		}
+
+		rendered = append(rendered, style.Render(strings.Join(lines, "\n")), " ")
+	}
+
+	return lipgloss.JoinHorizontal(lipgloss.Top, rendered...)
+}